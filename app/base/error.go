@@ -2,34 +2,27 @@ package base
 
 import (
 	"context"
-	e "errors"
-	"net/http"
 
 	"github.com/sabariramc/go-kit/errors"
+	"github.com/sabariramc/go-kit/log/correlation"
 )
 
-func ProcessError(ctx context.Context, err error) (int, []byte) {
-	var statusCode int
-	var body []byte
-	statusCode = http.StatusInternalServerError
-	var custErr *errors.Error
-	var httpErr *errors.HTTPError
-	if e.As(err, &httpErr) {
-		statusCode = httpErr.StatusCode
-		body, _ = httpErr.MarshalJSON()
-	} else if e.As(err, &custErr) {
-		statusCode = http.StatusInternalServerError
-		body, _ = custErr.MarshalJSON()
-	} else {
-		statusCode = http.StatusInternalServerError
-		body, _ = (&errors.Error{
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: "Unknown error",
-		}).MarshalJSON()
+// ProcessError encodes the error with the encoder set by errors.SetErrorEncoder and returns the status code, the content
+// type and the body, the content type depends on the encoder.
+func ProcessError(ctx context.Context, err error) (statusCode int, contentType string, body []byte) {
+	return errors.EncodeError(ctx, err)
+}
+
+// NewProblemEncoder returns an RFC 9457 problem details encoder setting the instance to the correlation ID of the
+// request, install it with errors.SetErrorEncoder.
+func NewProblemEncoder(typeBaseURI string) *errors.ProblemEncoder {
+	return &errors.ProblemEncoder{
+		TypeBaseURI: typeBaseURI,
+		Instance: func(ctx context.Context) string {
+			if corr, ok := correlation.ExtractCorrelationParam(ctx); ok && corr != nil {
+				return corr.CorrelationID
+			}
+			return ""
+		},
 	}
-	res := make([]byte, 0, len(body)+100)
-	res = append(res, []byte("{\"error\": ")...)
-	res = append(res, body...)
-	res = append(res, []byte("}")...)
-	return statusCode, res
 }
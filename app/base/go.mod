@@ -4,7 +4,7 @@ go 1.24.5
 
 require (
 	github.com/sabariramc/go-kit/env v1.0.0
-	github.com/sabariramc/go-kit/errors v1.0.1
+	github.com/sabariramc/go-kit/errors v1.0.2
 	github.com/sabariramc/go-kit/log v1.3.1
 )
 
@@ -15,3 +15,5 @@ require (
 	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
 )
+
+replace github.com/sabariramc/go-kit/errors => ../../errors
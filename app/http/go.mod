@@ -6,17 +6,32 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/julienschmidt/httprouter v1.3.0
 	github.com/rs/zerolog v1.34.0
-	github.com/sabariramc/go-kit/app/base v1.0.1
-	github.com/sabariramc/go-kit/errors v1.0.1
-	github.com/sabariramc/go-kit/instrumentation v1.0.1
+	github.com/sabariramc/go-kit/app/base v1.0.4
+	github.com/sabariramc/go-kit/errors v1.0.2
+	github.com/sabariramc/go-kit/instrumentation v1.0.2
+	github.com/sabariramc/go-kit/json v1.0.0
 	github.com/sabariramc/go-kit/log v1.3.1
+	github.com/sabariramc/go-kit/validate v1.0.0
 	gotest.tools/v3 v3.5.2
 )
 
 require (
 	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/sabariramc/go-kit/env v1.0.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
 )
+
+replace github.com/sabariramc/go-kit/instrumentation => ../../instrumentation
+
+replace github.com/sabariramc/go-kit/errors => ../../errors
+
+replace github.com/sabariramc/go-kit/app/base => ../base
+
+replace github.com/sabariramc/go-kit/validate => ../../validate
+
+replace github.com/sabariramc/go-kit/json => ../../json
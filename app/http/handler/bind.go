@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sabariramc/go-kit/errors"
+	kitjson "github.com/sabariramc/go-kit/json"
+)
+
+// Sources of the request values bound by Bind, they are also the struct tags naming the value of a field.
+const (
+	SourcePath   = "path"
+	SourceQuery  = "query"
+	SourceHeader = "header"
+	SourceBody   = "body"
+)
+
+// ErrorCodeInvalidRequest is the error code of the error returned when a request cannot be bound.
+const ErrorCodeInvalidRequest = "INVALID_REQUEST"
+
+// FieldError is a request value that failed to bind.
+type FieldError struct {
+	Field   string `json:"field"`
+	In      string `json:"in"`
+	Message string `json:"message"`
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Bind decodes the request into v, a pointer to a struct. Fields tagged path and query are bound from the path and
+// query parameters, fields tagged header through json.HeaderJSON and the body through json.BodyJSON. Fields tagged
+// path, query or header are only bound from their source. It returns a 400 errors.HTTPError listing the fields that
+// failed to bind.
+func Bind(r *http.Request, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("handler.Bind: expected a non nil pointer, got %T", v)
+	}
+	var fieldErrs []FieldError
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("handler.Bind: error reading body: %w", err)
+		}
+		if len(body) > 0 {
+			if err := kitjson.BodyJSON.Unmarshal(body, v); err != nil {
+				fieldErrs = append(fieldErrs, FieldError{Field: SourceBody, In: SourceBody, Message: err.Error()})
+			}
+		}
+	}
+	elem := rv.Elem()
+	if elem.Kind() != reflect.Struct {
+		return newBindError(fieldErrs)
+	}
+	params := httprouter.ParamsFromContext(r.Context())
+	query := r.URL.Query()
+	for _, f := range boundFields(elem) {
+		if _, ok := f.field.Tag.Lookup(SourceBody); !ok {
+			f.value.Set(reflect.Zero(f.value.Type()))
+		}
+		var err error
+		switch f.in {
+		case SourcePath:
+			if value := params.ByName(f.name); value != "" {
+				err = setValue(f.value, []string{value})
+			}
+		case SourceQuery:
+			if values, ok := query[f.name]; ok {
+				err = setValue(f.value, values)
+			}
+		case SourceHeader:
+			if values := r.Header.Values(f.name); len(values) > 0 {
+				err = bindHeader(f.parent, f.value.Type(), f.name, values)
+			}
+		}
+		if err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: f.name, In: f.in, Message: err.Error()})
+		}
+	}
+	return newBindError(fieldErrs)
+}
+
+// boundField is a struct field bound from the path, the query or the headers.
+type boundField struct {
+	field  reflect.StructField
+	value  reflect.Value
+	parent reflect.Value // parent is the struct holding the field.
+	name   string
+	in     string
+}
+
+// boundFields returns the fields of the struct tagged path, query or header, including those of embedded structs.
+func boundFields(v reflect.Value) []boundField {
+	var fields []boundField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		found := false
+		for _, in := range []string{SourcePath, SourceQuery, SourceHeader} {
+			name, ok := field.Tag.Lookup(in)
+			if !ok || name == "-" {
+				continue
+			}
+			fields = append(fields, boundField{field: field, value: v.Field(i), parent: v, name: strings.Split(name, ",")[0], in: in})
+			found = true
+			break
+		}
+		if !found && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, boundFields(v.Field(i))...)
+		}
+	}
+	return fields
+}
+
+// bindHeader decodes the header values into the field of type t of the struct through json.HeaderJSON, values that
+// are not valid JSON for a non string field are decoded as strings.
+func bindHeader(parent reflect.Value, t reflect.Type, name string, values []string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var raw []byte
+	var err error
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String {
+		raw, err = kitjson.HeaderJSON.Marshal(values)
+	} else if t.Kind() != reflect.String && kitjson.HeaderJSON.Valid([]byte(values[0])) {
+		raw = []byte(values[0])
+	} else {
+		raw, err = kitjson.HeaderJSON.Marshal(values[0])
+	}
+	if err != nil {
+		return err
+	}
+	key, _ := kitjson.HeaderJSON.Marshal(name)
+	return kitjson.HeaderJSON.Unmarshal(append(append(append([]byte("{"), key...), ':'), append(raw, '}')...), parent.Addr().Interface())
+}
+
+// setValue parses the string values into the field.
+func setValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if v.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	value := values[0]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// newBindError returns a 400 errors.HTTPError listing the fields, or nil when there are none.
+func newBindError(fieldErrs []FieldError) error {
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	return &errors.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Err: &errors.Error{
+			Code:        ErrorCodeInvalidRequest,
+			Message:     "Invalid request",
+			Description: map[string]any{"fields": fieldErrs},
+		},
+	}
+}
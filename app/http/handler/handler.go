@@ -2,15 +2,30 @@ package handler
 
 import (
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/sabariramc/go-kit/app/http/middleware"
+	"github.com/sabariramc/go-kit/app/http/openapi"
+	"github.com/sabariramc/go-kit/app/http/route"
 )
 
+// Router registers routes on an httprouter.Router. Middleware added with Use apply to every route of the router and
+// its groups, including routes registered before the call.
 type Router struct {
 	*httprouter.Router
-	middleware      []middleware.Middleware
-	builtMiddleware []middleware.Middleware
+	prefix     string
+	parent     *Router
+	state      *routerState
+	middleware []middleware.Middleware
+}
+
+// routerState is shared by a router and its groups.
+type routerState struct {
+	mu         sync.RWMutex
+	generation atomic.Uint64 // generation is incremented when middleware are added, to rebuild the route handlers.
+	routes     []openapi.Route
 }
 
 func New(opt ...Option) (*Router, error) {
@@ -18,41 +33,135 @@ func New(opt ...Option) (*Router, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Router{Router: cfg.Router}, nil
+	return &Router{Router: cfg.Router, state: &routerState{}}, nil
 }
 
+// Use adds middleware to the router, the first middleware added is the outermost.
 func (r *Router) Use(middleware ...middleware.Middleware) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
 	r.middleware = append(r.middleware, middleware...)
-	r.buildMiddleware()
+	r.state.generation.Add(1)
+}
+
+// Group returns a router registering its routes under the prefix. The middleware of the group apply to its routes
+// only, inside the middleware of the parent router, and groups can be nested.
+func (r *Router) Group(prefix string, middleware ...middleware.Middleware) *Router {
+	return &Router{
+		Router:     r.Router,
+		prefix:     r.prefix + prefix,
+		parent:     r,
+		state:      r.state,
+		middleware: middleware,
+	}
 }
 
-func (r *Router) buildMiddleware() {
-	r.builtMiddleware = make([]middleware.Middleware, len(r.middleware))
-	for i, j := 0, len(r.middleware)-1; i <= j; i, j = i+1, j-1 {
-		r.builtMiddleware[j] = r.middleware[i]
-		r.builtMiddleware[i] = r.middleware[j]
+// chain returns the middleware of the router and its parents, outermost first, must be called with the lock held.
+func (r *Router) chain() []middleware.Middleware {
+	if r.parent == nil {
+		return append([]middleware.Middleware(nil), r.middleware...)
 	}
+	return append(r.parent.chain(), r.middleware...)
 }
 
-func (r *Router) getHandler(handler http.Handler) http.Handler {
-	for _, mw := range r.builtMiddleware {
-		handler = mw(handler)
+func (r *Router) Handler(method, path string, handler http.Handler, opt ...RouteOption) {
+	cfg := NewRouteConfig(opt...)
+	rt := openapi.Route{Method: method, Path: r.prefix + path, Name: cfg.Metadata.Name, Tags: cfg.Metadata.Tags}
+	if typed, ok := handler.(openapi.Typed); ok {
+		rt.Request, rt.Response = typed.Types()
 	}
+	r.state.mu.Lock()
+	r.state.routes = append(r.state.routes, rt)
+	r.state.mu.Unlock()
+	r.handle(method, path, handler, cfg)
+}
+
+func (r *Router) handle(method, path string, handler http.Handler, cfg RouteConfig) {
+	r.Router.Handler(method, r.prefix+path, &routeHandler{
+		router:  r,
+		handler: handler,
+		pattern: r.prefix + path,
+		config:  cfg,
+	})
+}
+
+func (r *Router) HandlerFunc(method, path string, handler http.HandlerFunc, opt ...RouteOption) {
+	r.Handler(method, path, handler, opt...)
+}
+
+func (r *Router) HandlePath(path string, handler http.Handler, opt ...RouteOption) {
+	r.Handler(http.MethodGet, path, handler, opt...)
+	r.Handler(http.MethodPost, path, handler, opt...)
+	r.Handler(http.MethodPut, path, handler, opt...)
+	r.Handler(http.MethodDelete, path, handler, opt...)
+	r.Handler(http.MethodPatch, path, handler, opt...)
+}
+
+// routeHandler serves a route through the middleware of its router, the route pattern and metadata are stored in the
+// request context so that middleware can label requests by route.
+type routeHandler struct {
+	router  *Router
+	handler http.Handler
+	pattern string
+	config  RouteConfig
+	built   atomic.Pointer[builtHandler]
+}
+
+// builtHandler is the handler of a route wrapped in the middleware of a generation.
+type builtHandler struct {
+	generation uint64
+	handler    http.Handler
+}
+
+func (h *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := route.WithMetadata(route.WithPattern(r.Context(), h.pattern), h.config.Metadata)
+	h.current().ServeHTTP(w, r.WithContext(ctx))
+}
+
+// current returns the handler wrapped in the current middleware, rebuilding it when middleware were added.
+func (h *routeHandler) current() http.Handler {
+	state := h.router.state
+	if built := h.built.Load(); built != nil && built.generation == state.generation.Load() {
+		return built.handler
+	}
+	state.mu.RLock()
+	generation := state.generation.Load()
+	chain := append(h.router.chain(), h.config.Middleware...)
+	state.mu.RUnlock()
+	handler := h.handler
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	h.built.Store(&builtHandler{generation: generation, handler: handler})
 	return handler
 }
 
-func (r *Router) Handler(method, path string, handler http.Handler) {
-	r.Router.Handler(method, path, r.getHandler(handler))
+// Routes returns the routes registered with Handler, HandlerFunc and HandlePath on the router and its groups.
+func (r *Router) Routes() []openapi.Route {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	return append([]openapi.Route(nil), r.state.routes...)
 }
 
-func (r *Router) HandlerFunc(method, path string, handler http.HandlerFunc) {
-	r.Router.Handler(method, path, r.getHandler(handler))
+// OpenAPI returns the OpenAPI document of the registered routes.
+func (r *Router) OpenAPI(info openapi.Info) *openapi.Document {
+	return openapi.Generate(info, r.Routes())
 }
 
-func (r *Router) HandlePath(path string, handler http.Handler) {
-	r.Handler(http.MethodGet, path, handler)
-	r.Handler(http.MethodPost, path, handler)
-	r.Handler(http.MethodPut, path, handler)
-	r.Handler(http.MethodDelete, path, handler)
-	r.Handler(http.MethodPatch, path, handler)
+// HandleOpenAPI serves the OpenAPI document of the routes, at openapi.DefaultPath unless configured otherwise. The
+// document is generated on every request so that it includes routes registered afterwards, and the document routes
+// are left out of it.
+func (r *Router) HandleOpenAPI(info openapi.Info, opt ...openapi.Option) {
+	cfg := openapi.GetDefaultConfig(info)
+	for _, o := range opt {
+		o(&cfg)
+	}
+	r.handle(http.MethodGet, cfg.Path, openapi.Handler(func() *openapi.Document {
+		return r.OpenAPI(cfg.Info)
+	}), RouteConfig{})
+	if cfg.DocsPath != "" && cfg.DocsUI != nil {
+		for path, h := range cfg.DocsUI.Handlers(cfg.Info.Title, r.prefix+cfg.Path, r.prefix+cfg.DocsPath) {
+			r.handle(http.MethodGet, cfg.DocsPath+path, h, RouteConfig{})
+		}
+	}
 }
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/sabariramc/go-kit/app/http/middleware"
+	"github.com/sabariramc/go-kit/app/http/route"
+)
+
+// RouteConfig contains the configuration of a route.
+type RouteConfig struct {
+	Metadata   route.Metadata
+	Middleware []middleware.Middleware // Middleware apply to the route only, inside the middleware of its router.
+}
+
+// RouteOption represents an option function for configuring a route.
+type RouteOption func(*RouteConfig)
+
+func NewRouteConfig(opt ...RouteOption) RouteConfig {
+	cfg := RouteConfig{}
+	for _, o := range opt {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// WithName sets the name of the route, used by logs and traces and as the operation ID in the OpenAPI document.
+func WithName(name string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Metadata.Name = name
+	}
+}
+
+// WithTags adds tags to the route, they group operations in the OpenAPI document.
+func WithTags(tags ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Metadata.Tags = append(c.Metadata.Tags, tags...)
+	}
+}
+
+// WithScope sets the authorization scope required by the route, for authentication middleware to enforce.
+func WithScope(scope string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Metadata.Scope = scope
+	}
+}
+
+// WithMiddleware adds middleware to the route.
+func WithMiddleware(middleware ...middleware.Middleware) RouteOption {
+	return func(c *RouteConfig) {
+		c.Middleware = append(c.Middleware, middleware...)
+	}
+}
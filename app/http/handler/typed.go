@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/sabariramc/go-kit/app/http/constant"
+	"github.com/sabariramc/go-kit/errors"
+	"github.com/sabariramc/go-kit/validate"
+)
+
+// Validator validates typed requests, fields are named in violations by the tag they are bound from.
+var Validator = validate.New(validate.WithNameTags(SourcePath, SourceQuery, SourceHeader, SourceBody, "json"))
+
+// Writer writes the responses of typed handlers, *http.Server implements it.
+type Writer interface {
+	WriteJSONWithStatusCode(ctx context.Context, w http.ResponseWriter, statusCode int, responseBody any)
+	WriteErrorResponse(ctx context.Context, w http.ResponseWriter, err error)
+}
+
+// StatusCoder is implemented by responses that are not sent with 200 OK.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// TypedHandler is an http.Handler calling a function with the request bound into Req and writing its Resp as JSON.
+type TypedHandler[Req, Resp any] struct {
+	fn        func(ctx context.Context, req Req) (Resp, error)
+	writer    Writer
+	validator *validate.Validator
+}
+
+// Typed creates a TypedHandler for fn. The request is bound into Req with Bind and validated with Validator, binding
+// and validation failures are written as 400 errors. Resp is written as JSON, with the status code of Resp when it
+// implements StatusCoder, and errors are written with the encoder set by errors.SetErrorEncoder.
+func Typed[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) *TypedHandler[Req, Resp] {
+	return &TypedHandler[Req, Resp]{fn: fn, writer: jsonWriter{}, validator: Validator}
+}
+
+// WithWriter sets the writer of the responses, such as the *http.Server of the application.
+func (h *TypedHandler[Req, Resp]) WithWriter(w Writer) *TypedHandler[Req, Resp] {
+	h.writer = w
+	return h
+}
+
+// WithValidator sets the validator of the requests, nil disables validation.
+func (h *TypedHandler[Req, Resp]) WithValidator(v *validate.Validator) *TypedHandler[Req, Resp] {
+	h.validator = v
+	return h
+}
+
+// Types returns the request and response types, they describe the route in the OpenAPI document.
+func (h *TypedHandler[Req, Resp]) Types() (reflect.Type, reflect.Type) {
+	return reflect.TypeFor[Req](), reflect.TypeFor[Resp]()
+}
+
+func (h *TypedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req Req
+	if err := Bind(r, &req); err != nil {
+		h.writer.WriteErrorResponse(ctx, w, err)
+		return
+	}
+	if h.validator != nil {
+		if err := h.validator.Validate(&req); err != nil {
+			h.writer.WriteErrorResponse(ctx, w, err)
+			return
+		}
+	}
+	res, err := h.fn(ctx, req)
+	if err != nil {
+		h.writer.WriteErrorResponse(ctx, w, err)
+		return
+	}
+	statusCode := http.StatusOK
+	if sc, ok := any(res).(StatusCoder); ok {
+		statusCode = sc.StatusCode()
+	}
+	h.writer.WriteJSONWithStatusCode(ctx, w, statusCode, res)
+}
+
+// jsonWriter is the default Writer, it writes responses like http.Server without logging.
+type jsonWriter struct{}
+
+func (jsonWriter) WriteJSONWithStatusCode(ctx context.Context, w http.ResponseWriter, statusCode int, responseBody any) {
+	blob, ok := responseBody.([]byte)
+	if !ok {
+		var err error
+		blob, err = json.Marshal(responseBody)
+		if err != nil {
+			errors.WriteError(ctx, w, fmt.Errorf("handler.TypedHandler: error marshalling response: %w", err))
+			return
+		}
+	}
+	w.Header().Set(constant.HTTPHeaderContentType, constant.HTTPContentTypeJSON)
+	w.WriteHeader(statusCode)
+	w.Write(blob)
+}
+
+func (jsonWriter) WriteErrorResponse(ctx context.Context, w http.ResponseWriter, err error) {
+	errors.WriteError(ctx, w, err)
+}
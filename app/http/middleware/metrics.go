@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sabariramc/go-kit/app/http/route"
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Metric names recorded by MetricsMiddleware.
+const (
+	MetricServerRequests        = "http.server.requests"
+	MetricServerRequestDuration = "http.server.request.duration"
+)
+
+// MetricsMiddleware records the count and latency of requests by method, route pattern and status code.
+// It should be registered before PanicHandleMiddleware so that the status code written for a panic is recorded.
+func MetricsMiddleware(meter span.Meter) Middleware {
+	requests := meter.Counter(MetricServerRequests, span.UnitCount, "Number of HTTP requests served")
+	duration := meter.Histogram(MetricServerRequestDuration, span.UnitSeconds, "Duration of HTTP requests served")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st := time.Now()
+			sw := newResponseWriter(w)
+			next.ServeHTTP(sw, r)
+			pattern, ok := route.Pattern(r.Context())
+			if !ok {
+				pattern = "unmatched"
+			}
+			attrs := []span.Attribute{
+				span.Attr(span.HTTPRequestMethod, r.Method),
+				span.Attr(span.HTTPRoute, pattern),
+				span.Attr(span.HTTPStatusCode, strconv.Itoa(sw.statusCode)),
+			}
+			requests.Add(r.Context(), 1, attrs...)
+			duration.Record(r.Context(), time.Since(st).Seconds(), attrs...)
+		})
+	}
+}
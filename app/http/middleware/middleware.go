@@ -9,6 +9,9 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sabariramc/go-kit/app/base"
+	"github.com/sabariramc/go-kit/app/http/constant"
+	"github.com/sabariramc/go-kit/app/http/route"
+	"github.com/sabariramc/go-kit/errors"
 	span "github.com/sabariramc/go-kit/instrumentation"
 	"github.com/sabariramc/go-kit/log"
 	"github.com/sabariramc/go-kit/log/correlation"
@@ -69,7 +72,11 @@ func RequestTimerMiddleware(log *log.Logger) Middleware {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			st := time.Now()
 			next.ServeHTTP(w, r)
-			log.Info(r.Context()).Str("method", r.Method).Str("url", r.URL.Path).Dur("latencyInMS", time.Duration(time.Since(st).Milliseconds())).Msg("Request completed")
+			ev := log.Info(r.Context()).Str("method", r.Method).Str("url", r.URL.Path)
+			if metadata, ok := route.GetMetadata(r.Context()); ok && metadata.Name != "" {
+				ev = ev.Str("route", metadata.Name)
+			}
+			ev.Dur("latencyInMS", time.Duration(time.Since(st).Milliseconds())).Msg("Request completed")
 		})
 	}
 }
@@ -86,7 +93,7 @@ func PanicHandleMiddleware(log *log.Logger, tr span.SpanOp) Middleware {
 					if !ok {
 						err = fmt.Errorf("error occurred during request processing")
 					}
-					statusCode, body := base.ProcessError(r.Context(), err)
+					statusCode, contentType, body := errors.EncodeError(r.Context(), err)
 					if tr != nil {
 						sp, ok := tr.GetSpanFromContext(r.Context())
 						if ok {
@@ -94,6 +101,7 @@ func PanicHandleMiddleware(log *log.Logger, tr span.SpanOp) Middleware {
 							sp.SetStatus(statusCode, http.StatusText(statusCode))
 						}
 					}
+					w.Header().Set(constant.HTTPHeaderContentType, contentType)
 					w.WriteHeader(statusCode)
 					w.Write(body)
 				}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sabariramc/go-kit/app/http/route"
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Attribute keys set on server spans in addition to the instrumentation keys.
+const (
+	attrURLPath          = "url.path"
+	attrURLScheme        = "url.scheme"
+	attrUserAgent        = "user_agent.original"
+	attrResponseBodySize = "http.response.body.size"
+	attrRouteName        = "http.route.name"
+)
+
+// TracingMiddleware starts a server span for every request and finishes it once the response is written.
+//
+// When tr implements span.Propagator the trace context in the request headers, such as W3C traceparent and baggage
+// or Datadog headers, is extracted so that the span continues the caller's trace. The span is named after the method
+// and the route pattern, for example "GET /users/:id", so it must be registered through handler.Router.Use for the
+// pattern to be known; outside a router only the method is used. It should be registered before
+// SetCorrelationMiddleware and PanicHandleMiddleware, which decorate the span started here.
+func TracingMiddleware(tr span.SpanOp) Middleware {
+	propagator, _ := tr.(span.Propagator)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if propagator != nil {
+				ctx = propagator.Extract(ctx, span.HeaderCarrier(r.Header))
+			}
+			name := r.Method
+			pattern, ok := route.Pattern(ctx)
+			if ok {
+				name += " " + pattern
+			}
+			ctx, sp := tr.NewSpanFromContext(ctx, name, span.SpanKindServer, name)
+			defer sp.Finish()
+			sp.SetAttribute(span.HTTPRequestMethod, r.Method)
+			if ok {
+				sp.SetAttribute(span.HTTPRoute, pattern)
+			}
+			if metadata, ok := route.GetMetadata(ctx); ok && metadata.Name != "" {
+				sp.SetAttribute(attrRouteName, metadata.Name)
+			}
+			sp.SetAttribute(attrURLPath, r.URL.Path)
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			sp.SetAttribute(attrURLScheme, scheme)
+			if ua := r.UserAgent(); ua != "" {
+				sp.SetAttribute(attrUserAgent, ua)
+			}
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+			sp.SetAttribute(span.HTTPStatusCode, rw.statusCode)
+			sp.SetAttribute(attrResponseBodySize, rw.size)
+			if rw.statusCode < http.StatusBadRequest || rw.statusCode >= http.StatusInternalServerError {
+				// client errors leave the status of a server span unset
+				sp.SetStatus(rw.statusCode, http.StatusText(rw.statusCode))
+			}
+		})
+	}
+}
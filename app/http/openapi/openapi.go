@@ -0,0 +1,231 @@
+// Package openapi generates OpenAPI 3.1 documents from the routes registered on a handler.Router.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas referenced from the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem holds the operations of a path.
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+// Operation is an API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of an operation.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route is a registered route, Request and Response are the types of typed handlers and nil otherwise.
+type Route struct {
+	Method   string
+	Path     string
+	Name     string // Name is the operation ID, derived from the method and the path when empty.
+	Tags     []string
+	Request  reflect.Type
+	Response reflect.Type
+}
+
+// Typed is implemented by handlers whose request and response types are described in the document.
+type Typed interface {
+	Types() (request, response reflect.Type)
+}
+
+// Names of the schemas of error responses. Error responses are described with the content type and the schema of the
+// encoder installed with errors.SetErrorEncoder when the document is generated.
+const (
+	ErrorSchemaName   = "Error"   // ErrorSchemaName is the schema of the envelope written by errors.EnvelopeEncoder.
+	ProblemSchemaName = "Problem" // ProblemSchemaName is the schema of the problem details written by errors.ProblemEncoder.
+)
+
+// Generate returns the document of the routes.
+func Generate(info Info, routes []Route) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+	}
+	for _, route := range routes {
+		path, params := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		item.set(route.Method, g.operation(route, path, params))
+	}
+	if len(g.components) > 0 {
+		doc.Components = &Components{Schemas: g.components}
+	}
+	return doc
+}
+
+func (g *generator) operation(route Route, path string, pathParams []string) *Operation {
+	op := &Operation{
+		OperationID: route.Name,
+		Tags:        route.Tags,
+		Responses:   make(map[string]*Response),
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(route.Method, path)
+	}
+	declared := make(map[string]bool)
+	if route.Request != nil {
+		var body *Schema
+		op.Parameters, body = g.request(route.Request)
+		for _, p := range op.Parameters {
+			if p.In == "path" {
+				declared[p.Name] = true
+			}
+		}
+		if body != nil {
+			op.RequestBody = &RequestBody{
+				Required: len(body.Required) > 0,
+				Content:  map[string]*MediaType{"application/json": {Schema: body}},
+			}
+		}
+	}
+	for _, name := range pathParams {
+		if !declared[name] {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if route.Response == nil {
+		op.Responses["default"] = &Response{Description: "Response"}
+		return op
+	}
+	statusCode := responseStatusCode(route.Response)
+	op.Responses[strconv.Itoa(statusCode)] = &Response{
+		Description: http.StatusText(statusCode),
+		Content:     map[string]*MediaType{"application/json": {Schema: g.schema(route.Response, "json")}},
+	}
+	op.Responses["400"] = g.errorResponse("Invalid request")
+	op.Responses["default"] = g.errorResponse("Error")
+	return op
+}
+
+func (item *PathItem) set(method string, op *Operation) {
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodOptions:
+		item.Options = op
+	case http.MethodHead:
+		item.Head = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+// convertPath converts an httprouter path, such as /users/:id, to an OpenAPI path and returns its parameters.
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID returns an identifier such as getUsersId for GET /users/{id}.
+func operationID(method, path string) string {
+	sb := &strings.Builder{}
+	sb.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// responseStatusCode returns the status code of the StatusCode method of the response type, or 200.
+func responseStatusCode(t reflect.Type) int {
+	v := reflect.New(t).Elem()
+	if t.Kind() == reflect.Pointer {
+		v = reflect.New(t.Elem())
+	}
+	if sc, ok := v.Interface().(interface{ StatusCode() int }); ok {
+		return sc.StatusCode()
+	}
+	return http.StatusOK
+}
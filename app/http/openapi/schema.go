@@ -0,0 +1,340 @@
+package openapi
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	e "errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sabariramc/go-kit/errors"
+)
+
+// Schema is a JSON schema.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	requestSourceTags   = []string{"path", "query", "header"} // requestSourceTags are the tags of handler.Bind naming parameters.
+	validateTag         = "validate"
+	componentsSchemaRef = "#/components/schemas/"
+)
+
+// generator builds schemas, named structs described with their json tags are shared as components.
+type generator struct {
+	components       map[string]*Schema
+	errorContentType string // errorContentType is the content type of the error responses of the installed encoder.
+	names            map[reflect.Type]string
+	inline           map[reflect.Type]bool // inline holds the structs being inlined, to stop on recursive types.
+}
+
+func newGenerator() *generator {
+	_, errorContentType, _ := errors.EncodeError(context.Background(), e.New("openapi"))
+	return &generator{
+		errorContentType: errorContentType,
+		components:       make(map[string]*Schema),
+		names:            make(map[reflect.Type]string),
+		inline:           make(map[reflect.Type]bool),
+	}
+}
+
+// request returns the parameters and the body schema of a request type bound by handler.Bind.
+func (g *generator) request(t reflect.Type) ([]*Parameter, *Schema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, g.schema(t, "body")
+	}
+	var params []*Parameter
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.inline[t] = true
+	defer delete(g.inline, t)
+	forEachField(t, func(f reflect.StructField) {
+		for _, in := range requestSourceTags {
+			name, ok := f.Tag.Lookup(in)
+			if !ok {
+				continue
+			}
+			if name, _, _ = strings.Cut(name, ","); name == "" || name == "-" {
+				return
+			}
+			s := g.schema(f.Type, "json")
+			required := applyRules(s, f.Tag.Get(validateTag))
+			params = append(params, &Parameter{Name: name, In: in, Required: required || in == "path", Schema: s})
+			return
+		}
+		g.property(body, f, "body")
+	}, "body")
+	if len(body.Properties) == 0 {
+		return params, nil
+	}
+	return params, body
+}
+
+// schema returns the schema of a type, struct field names are read from the tag.
+func (g *generator) schema(t reflect.Type, tag string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), tag)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), tag)}
+	case reflect.Struct:
+		if tag == "json" && t.Name() != "" {
+			return g.ref(t)
+		}
+		if g.inline[t] {
+			return &Schema{Type: "object"}
+		}
+		g.inline[t] = true
+		defer delete(g.inline, t)
+		return g.object(t, tag)
+	}
+	return &Schema{}
+}
+
+// ref returns a reference to the component of a named struct, adding the component on first use.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		for i := 2; g.components[name] != nil; i++ {
+			name = componentName(t) + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		g.components[name] = &Schema{}
+		*g.components[name] = *g.object(t, "json")
+	}
+	return &Schema{Ref: componentsSchemaRef + name}
+}
+
+func (g *generator) object(t reflect.Type, tag string) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	forEachField(t, func(f reflect.StructField) {
+		g.property(s, f, tag)
+	}, tag)
+	return s
+}
+
+// property adds the field to the properties of the object schema.
+func (g *generator) property(s *Schema, f reflect.StructField, tag string) {
+	name := f.Name
+	if value, ok := f.Tag.Lookup(tag); ok {
+		if value, _, _ = strings.Cut(value, ","); value == "-" {
+			return
+		} else if value != "" {
+			name = value
+		}
+	}
+	prop := g.schema(f.Type, tag)
+	if applyRules(prop, f.Tag.Get(validateTag)) {
+		s.Required = append(s.Required, name)
+	}
+	s.Properties[name] = prop
+}
+
+// errorResponse describes an error response of the installed encoder, the body of custom encoders has no schema.
+func (g *generator) errorResponse(description string) *Response {
+	contentType, schema := g.errorContentType, &Schema{}
+	switch contentType {
+	case errors.ContentTypeJSON:
+		g.components[ErrorSchemaName] = envelopeSchema()
+		schema = &Schema{Ref: componentsSchemaRef + ErrorSchemaName}
+	case errors.ContentTypeProblemJSON:
+		g.components[ProblemSchemaName] = problemSchema()
+		schema = &Schema{Ref: componentsSchemaRef + ProblemSchemaName}
+	case "":
+		contentType = errors.ContentTypeJSON
+	}
+	return &Response{
+		Description: description,
+		Content:     map[string]*MediaType{contentType: {Schema: schema}},
+	}
+}
+
+// envelopeSchema returns the schema of the {"error": {...}} envelope written by errors.EnvelopeEncoder.
+func envelopeSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"code":        {Type: "string"},
+					"message":     {Type: "string"},
+					"description": {},
+				},
+				Required: []string{"code"},
+			},
+		},
+		Required: []string{"error"},
+	}
+}
+
+// problemSchema returns the schema of the RFC 9457 problem details written by errors.ProblemEncoder, the description
+// of the error is merged in as extension members.
+func problemSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type":     {Type: "string", Format: "uri-reference"},
+			"title":    {Type: "string"},
+			"status":   {Type: "integer"},
+			"detail":   {Type: "string"},
+			"instance": {Type: "string"},
+		},
+		AdditionalProperties: &Schema{},
+	}
+}
+
+// forEachField calls fn for the exported fields of the struct, flattening embedded structs without a name in the tag.
+func forEachField(t reflect.Type, fn func(f reflect.StructField), tag string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			forEachField(ft, fn, tag)
+			continue
+		}
+		fn(f)
+	}
+}
+
+// componentName returns the name of a struct type without the characters not allowed in component names.
+func componentName(t reflect.Type) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, t.Name())
+}
+
+// applyRules annotates the schema with the rules of a validate tag and reports whether the value is required.
+func applyRules(s *Schema, tag string) bool {
+	required := false
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "dive":
+			if s.Items != nil {
+				applyRules(s.Items, tag)
+			} else if s.AdditionalProperties != nil {
+				applyRules(s.AdditionalProperties, tag)
+			}
+			return required
+		case "required":
+			required = true
+		case "min", "max", "len":
+			setBound(s, name, param)
+		case "regex":
+			s.Pattern = param
+		case "enum":
+			for _, value := range strings.Split(param, "|") {
+				if n, err := strconv.ParseFloat(value, 64); err == nil && (s.Type == "integer" || s.Type == "number") {
+					s.Enum = append(s.Enum, n)
+				} else {
+					s.Enum = append(s.Enum, value)
+				}
+			}
+		case "email":
+			s.Format = "email"
+		case "uuid":
+			s.Format = "uuid"
+		}
+	}
+	return required
+}
+
+// setBound sets the bound of a min, max or len rule on the schema, according to its type.
+func setBound(s *Schema, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "integer", "number":
+		if rule == "min" || rule == "len" {
+			s.Minimum = &n
+		}
+		if rule == "max" || rule == "len" {
+			s.Maximum = &n
+		}
+		return
+	}
+	length := uint64(n)
+	lower, upper := &s.MinLength, &s.MaxLength
+	if s.Type == "array" {
+		lower, upper = &s.MinItems, &s.MaxItems
+	} else if s.Type != "string" {
+		return
+	}
+	if rule == "min" || rule == "len" {
+		*lower = &length
+	}
+	if rule == "max" || rule == "len" {
+		*upper = &length
+	}
+}
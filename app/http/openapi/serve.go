@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Default paths of the document and of the docs UI.
+const (
+	DefaultPath     = "/meta/openapi.json"
+	DefaultDocsPath = "/meta/docs"
+)
+
+// CommandName is the command line argument handled by Command.
+const CommandName = "openapi"
+
+// Config contains the configuration of the served document.
+type Config struct {
+	Info     Info
+	Path     string // Path serves the document.
+	DocsPath string // DocsPath serves the docs UI, it is disabled when empty.
+	DocsUI   DocsUI // DocsUI is the docs UI served at DocsPath.
+}
+
+// DocsUI is a docs UI rendering the document, such as swaggerui.UI. It lives in its own package so that services not
+// serving a docs UI do not carry its assets.
+type DocsUI interface {
+	// Handlers returns the handlers of the UI by path relative to docsURL, the empty path being the docs page.
+	Handlers(title, specURL, docsURL string) map[string]http.Handler
+}
+
+// Option represents an option function for configuring the served document.
+type Option func(*Config)
+
+// GetDefaultConfig returns a Config serving the document at DefaultPath without docs UI.
+func GetDefaultConfig(info Info) Config {
+	return Config{
+		Info: info,
+		Path: DefaultPath,
+	}
+}
+
+// WithPath sets the path of the document.
+func WithPath(path string) Option {
+	return func(c *Config) {
+		c.Path = path
+	}
+}
+
+// WithDocs serves the docs UI rendering the document at path.
+func WithDocs(path string, ui DocsUI) Option {
+	return func(c *Config) {
+		c.DocsPath = path
+		c.DocsUI = ui
+	}
+}
+
+// Handler returns a handler writing the document returned by doc.
+func Handler(doc func() *Document) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blob, err := json.Marshal(doc())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(blob)
+	})
+}
+
+// Write writes the indented document, map keys are sorted so that the output is stable across runs.
+func Write(w io.Writer, doc *Document) error {
+	blob, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("openapi.Write: error encoding document: %w", err)
+	}
+	if _, err := w.Write(append(blob, '\n')); err != nil {
+		return fmt.Errorf("openapi.Write: %w", err)
+	}
+	return nil
+}
+
+// Command dumps the document when args, usually os.Args[1:], start with CommandName, and reports whether it did.
+// The document is written to the file given with -o, or to stdout. Applications call it in main after registering
+// their routes, so that the document can be generated at build time with `go run . openapi -o openapi.json`.
+func Command(args []string, stdout io.Writer, doc func() *Document) (bool, error) {
+	if len(args) == 0 || args[0] != CommandName {
+		return false, nil
+	}
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	output := flags.String("o", "", "file to write the document to, stdout when empty")
+	if err := flags.Parse(args[1:]); err != nil {
+		return true, fmt.Errorf("openapi.Command: %w", err)
+	}
+	if *output == "" {
+		return true, Write(stdout, doc())
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return true, fmt.Errorf("openapi.Command: error creating file: %w", err)
+	}
+	defer f.Close()
+	return true, Write(f, doc())
+}
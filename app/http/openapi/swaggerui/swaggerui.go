@@ -0,0 +1,64 @@
+// Package swaggerui serves a Swagger UI docs page rendering an OpenAPI document. The Swagger UI assets are embedded in
+// the binary, so services import the package only when they serve the docs UI:
+//
+//	router.HandleOpenAPI(info, openapi.WithDocs(openapi.DefaultDocsPath, swaggerui.UI{}))
+package swaggerui
+
+import (
+	"embed"
+	"html/template"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// Version is the version of the embedded Swagger UI assets, copied from the swagger-ui-dist package and distributed
+// under the Apache License 2.0 in LICENSE.
+const Version = "5.18.2"
+
+// Assets are the Swagger UI assets served by AssetsHandler.
+var Assets = []string{"swagger-ui.css", "swagger-ui-bundle.js"}
+
+//go:embed docs.html
+var docsHTML string
+
+//go:embed swagger-ui.css swagger-ui-bundle.js
+var assets embed.FS
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// UI is the Swagger UI, it implements openapi.DocsUI.
+type UI struct{}
+
+// Handlers returns the docs page and the Assets, served below the page.
+func (UI) Handlers(title, specURL, docsURL string) map[string]http.Handler {
+	handlers := map[string]http.Handler{"": DocsHandler(title, specURL, docsURL)}
+	for _, asset := range Assets {
+		handlers["/"+asset] = AssetsHandler()
+	}
+	return handlers
+}
+
+// DocsHandler returns a handler serving the docs page for the document at specURL. The page loads the Assets from
+// assetsURL, where they are served by AssetsHandler, so that it works without access to a CDN.
+func DocsHandler(title, specURL, assetsURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		docsTemplate.Execute(w, map[string]string{"Title": title, "SpecURL": specURL, "AssetsURL": assetsURL})
+	})
+}
+
+// AssetsHandler returns a handler serving the asset named by the last segment of the request path.
+func AssetsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Base(r.URL.Path)
+		blob, err := assets.ReadFile(name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write(blob)
+	})
+}
@@ -6,8 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/sabariramc/go-kit/app/base"
 	"github.com/sabariramc/go-kit/app/http/constant"
+	"github.com/sabariramc/go-kit/errors"
 )
 
 // ResponseWriter is a custom response writer that logs responses and request bodies.
@@ -57,10 +57,10 @@ func (h *Server) WriteResponse(ctx context.Context, w http.ResponseWriter, conte
 	h.WriteResponseWithStatusCode(ctx, w, http.StatusOK, contentType, responseBody)
 }
 
-// WriteErrorResponse writes an error response, logging the error and stack trace.
+// WriteErrorResponse writes an error response encoded with the encoder set by errors.SetErrorEncoder.
 func (h *Server) WriteErrorResponse(ctx context.Context, w http.ResponseWriter, err error) {
-	statusCode, body := base.ProcessError(ctx, err)
-	h.WriteJSONWithStatusCode(ctx, w, statusCode, body)
+	statusCode, contentType, body := errors.EncodeError(ctx, err)
+	h.WriteResponseWithStatusCode(ctx, w, statusCode, contentType, body)
 }
 
 // WriteResponseWithStatusCode writes a response with the specified status code and content type.
@@ -1,44 +1,74 @@
 package route
 
 import (
+	"context"
 	"net/http"
 
-	"github.com/sabariramc/go-kit/app/base"
-	"github.com/sabariramc/go-kit/app/http/constant"
 	"github.com/sabariramc/go-kit/errors"
 )
 
 // NotFound returns a handler function for responding with a 404 Not Found status.
 func NotFound() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add(constant.HTTPHeaderContentType, constant.HTTPContentTypeJSON)
-		w.WriteHeader(http.StatusNotFound)
-		err := &errors.Error{
-			Code:    "URL_NOT_FOUND",
-			Message: "URL Not Found",
-			Description: map[string]any{
-				"path": r.URL.Path,
+		errors.WriteError(r.Context(), w, &errors.HTTPError{
+			StatusCode: http.StatusNotFound,
+			Err: &errors.Error{
+				Code:    "URL_NOT_FOUND",
+				Message: "URL Not Found",
+				Description: map[string]any{
+					"path": r.URL.Path,
+				},
 			},
-		}
-		_, body := base.ProcessError(r.Context(), err)
-		w.Write(body)
+		})
 	}
 }
 
 // MethodNotAllowed returns a handler function for responding with a 405 Method Not Allowed status.
 func MethodNotAllowed() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add(constant.HTTPHeaderContentType, constant.HTTPContentTypeJSON)
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		err := &errors.Error{
-			Code:    "METHOD_NOT_ALLOWED",
-			Message: "Method Not Allowed",
-			Description: map[string]any{
-				"path":   r.URL.Path,
-				"method": r.Method,
+		errors.WriteError(r.Context(), w, &errors.HTTPError{
+			StatusCode: http.StatusMethodNotAllowed,
+			Err: &errors.Error{
+				Code:    "METHOD_NOT_ALLOWED",
+				Message: "Method Not Allowed",
+				Description: map[string]any{
+					"path":   r.URL.Path,
+					"method": r.Method,
+				},
 			},
-		}
-		_, body := base.ProcessError(r.Context(), err)
-		w.Write(body)
+		})
 	}
 }
+
+type patternKey struct{}
+
+// WithPattern returns a context carrying the route pattern, such as /users/:id, that matched the request.
+func WithPattern(ctx context.Context, pattern string) context.Context {
+	return context.WithValue(ctx, patternKey{}, pattern)
+}
+
+// Pattern returns the route pattern that matched the request, it is set by handler.Router for every registered route.
+func Pattern(ctx context.Context) (string, bool) {
+	pattern, ok := ctx.Value(patternKey{}).(string)
+	return pattern, ok
+}
+
+// Metadata describes a route, it is set by handler.Router for every registered route.
+type Metadata struct {
+	Name  string   // Name identifies the route in logs and traces.
+	Tags  []string // Tags group routes, for example in the OpenAPI document.
+	Scope string   // Scope is the authorization scope required by the route.
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a context carrying the metadata of the route that matched the request.
+func WithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// GetMetadata returns the metadata of the route that matched the request.
+func GetMetadata(ctx context.Context) (Metadata, bool) {
+	metadata, ok := ctx.Value(metadataKey{}).(Metadata)
+	return metadata, ok
+}
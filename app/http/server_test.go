@@ -2,17 +2,28 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
+	"github.com/sabariramc/go-kit/app/base"
 	srv "github.com/sabariramc/go-kit/app/http"
+	"github.com/sabariramc/go-kit/app/http/errorhandler"
 	"github.com/sabariramc/go-kit/app/http/handler"
+	"github.com/sabariramc/go-kit/app/http/middleware"
+	"github.com/sabariramc/go-kit/app/http/openapi"
+	"github.com/sabariramc/go-kit/app/http/openapi/swaggerui"
+	"github.com/sabariramc/go-kit/app/http/route"
+	"github.com/sabariramc/go-kit/errors"
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/instrumentation/memory"
 	"github.com/sabariramc/go-kit/log"
 	"github.com/sabariramc/go-kit/log/correlation"
 	"gotest.tools/v3/assert"
@@ -185,3 +196,329 @@ func BenchmarkRoutes(b *testing.B) {
 		})
 	}
 }
+
+func TestMetricsMiddleware(t *testing.T) {
+	meter := memory.NewMeter()
+	router, err := handler.New()
+	assert.NilError(t, err)
+	logger := log.New("TestServer", func(c *log.Config) { c.Target = io.Discard })
+	router.Use(middleware.MetricsMiddleware(meter), middleware.PanicHandleMiddleware(logger, nil))
+	router.HandlerFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	router.HandlerFunc(http.MethodGet, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("panic")
+	})
+	for _, path := range []string{"/users/1", "/users/2", "/panic"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Equal(t, meter.Sum(middleware.MetricServerRequests, span.Attr(span.HTTPRoute, "/users/:id"), span.Attr(span.HTTPStatusCode, "200")), float64(2))
+	assert.Equal(t, meter.Sum(middleware.MetricServerRequests, span.Attr(span.HTTPRoute, "/panic"), span.Attr(span.HTTPStatusCode, "500")), float64(1))
+	assert.Equal(t, meter.Count(middleware.MetricServerRequestDuration, span.Attr(span.HTTPRequestMethod, http.MethodGet)), 3)
+}
+
+type traceParentKey struct{}
+
+type testSpan struct {
+	name       string
+	kind       string
+	parent     string
+	attributes map[string]any
+	statusCode int
+	finished   bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any)           { s.attributes[key] = value }
+func (s *testSpan) SetStatus(statusCode int, description string) { s.statusCode = statusCode }
+func (s *testSpan) SetError(err error, stackTrace string)        {}
+func (s *testSpan) Finish()                                      { s.finished = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) NewSpanFromContext(ctx context.Context, operationName string, kind string, resourceName string) (context.Context, span.Span) {
+	parent, _ := ctx.Value(traceParentKey{}).(string)
+	sp := &testSpan{name: operationName, kind: kind, parent: parent, attributes: map[string]any{}}
+	t.spans = append(t.spans, sp)
+	return ctx, sp
+}
+
+func (t *testTracer) GetSpanFromContext(ctx context.Context) (span.Span, bool) {
+	if len(t.spans) == 0 {
+		return nil, false
+	}
+	return t.spans[len(t.spans)-1], true
+}
+
+func (t *testTracer) Inject(ctx context.Context, carrier span.Carrier) {
+	if parent, ok := ctx.Value(traceParentKey{}).(string); ok {
+		carrier.Set("traceparent", parent)
+	}
+}
+
+func (t *testTracer) Extract(ctx context.Context, carrier span.Carrier) context.Context {
+	if parent := carrier.Get("traceparent"); parent != "" {
+		return context.WithValue(ctx, traceParentKey{}, parent)
+	}
+	return ctx
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	tr := &testTracer{}
+	router, err := handler.New()
+	assert.NilError(t, err)
+	router.Use(middleware.TracingMiddleware(tr), middleware.SetCorrelationMiddleware(tr))
+	router.HandlerFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	})
+	router.HandlerFunc(http.MethodGet, "/missing/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing/1", nil))
+	assert.Equal(t, len(tr.spans), 2)
+	sp := tr.spans[0]
+	assert.Equal(t, sp.name, "GET /users/:id")
+	assert.Equal(t, sp.kind, span.SpanKindServer)
+	assert.Equal(t, sp.parent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, sp.attributes[span.HTTPRoute], "/users/:id")
+	assert.Equal(t, sp.attributes[span.HTTPStatusCode], http.StatusOK)
+	assert.Equal(t, sp.attributes["http.response.body.size"], 4)
+	assert.Assert(t, sp.attributes["correlationId"] != nil)
+	assert.Equal(t, sp.statusCode, http.StatusOK)
+	assert.Assert(t, sp.finished)
+	sp = tr.spans[1]
+	assert.Equal(t, sp.parent, "")
+	assert.Equal(t, sp.attributes[span.HTTPStatusCode], http.StatusNotFound)
+	assert.Equal(t, sp.statusCode, 0)
+}
+
+type createOrderRequest struct {
+	UserID   int64    `path:"id"`
+	Limit    *int     `query:"limit"`
+	Tags     []string `query:"tag"`
+	TenantID string   `header:"X-Tenant-Id"`
+	Item     string   `body:"item" validate:"required"`
+	Quantity int      `body:"quantity" validate:"max=10"`
+}
+
+type createOrderResponse struct {
+	UserID   int64    `json:"userId"`
+	Limit    int      `json:"limit"`
+	Tags     []string `json:"tags"`
+	TenantID string   `json:"tenantId"`
+	Item     string   `json:"item"`
+	Quantity int      `json:"quantity"`
+}
+
+func (createOrderResponse) StatusCode() int { return http.StatusCreated }
+
+func TestTypedHandler(t *testing.T) {
+	srv := New(t)
+	router, err := handler.New()
+	assert.NilError(t, err)
+	router.Handler(http.MethodPost, "/users/:id/orders", handler.Typed(func(ctx context.Context, req createOrderRequest) (createOrderResponse, error) {
+		if req.Item == "unknown" {
+			return createOrderResponse{}, &errors.HTTPError{StatusCode: http.StatusNotFound, Err: &errors.Error{Code: "ITEM_NOT_FOUND", Message: "item not found"}}
+		}
+		return createOrderResponse{UserID: req.UserID, Limit: *req.Limit, Tags: req.Tags, TenantID: req.TenantID, Item: req.Item, Quantity: req.Quantity}, nil
+	}).WithWriter(srv))
+	req := httptest.NewRequest(http.MethodPost, "/users/42/orders?limit=5&tag=a&tag=b", bytes.NewBufferString(`{"item":"book","quantity":2,"TenantID":"ignored"}`))
+	req.Header.Set("X-Tenant-Id", "tenant-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusCreated, w.Body.String())
+	assert.Equal(t, w.Body.String(), `{"userId":42,"limit":5,"tags":["a","b"],"tenantId":"tenant-1","item":"book","quantity":2}`)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/abc/orders?limit=x", bytes.NewBufferString(`{"item":"book","quantity":"two"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	var res map[string]map[string]any
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, res["error"]["code"], handler.ErrorCodeInvalidRequest)
+	fields := res["error"]["description"].(map[string]any)["fields"].([]any)
+	assert.Equal(t, len(fields), 3)
+	assert.DeepEqual(t, fields[1], map[string]any{"field": "id", "in": "path", "message": `invalid integer "abc"`})
+	assert.DeepEqual(t, fields[2], map[string]any{"field": "limit", "in": "query", "message": `invalid integer "x"`})
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1/orders?limit=1", bytes.NewBufferString(`{"quantity":20}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	assert.Equal(t, w.Body.String(), `{"error": {"code":"VALIDATION_FAILED","message":"Validation failed for item, quantity","description":[{"field":"item","rule":"required","message":"is required"},{"field":"quantity","rule":"max","message":"must be at most 10"}]}}`)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1/orders?limit=1", bytes.NewBufferString(`{"item":"unknown"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	assert.Equal(t, w.Body.String(), `{"error": {"code":"ITEM_NOT_FOUND","message":"item not found"}}`)
+}
+
+func TestOpenAPI(t *testing.T) {
+	router, err := handler.New()
+	assert.NilError(t, err)
+	router.Handler(http.MethodPost, "/users/:id/orders", handler.Typed(func(ctx context.Context, req createOrderRequest) (createOrderResponse, error) {
+		return createOrderResponse{}, nil
+	}))
+	router.HandlerFunc(http.MethodGet, "/files/*path", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleOpenAPI(openapi.Info{Title: "Orders", Version: "1.0.0"}, openapi.WithDocs(openapi.DefaultDocsPath, swaggerui.UI{}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapi.DefaultPath, nil))
+	assert.Equal(t, w.Code, http.StatusOK)
+	var doc map[string]any
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, doc["openapi"], "3.1.0")
+	paths := doc["paths"].(map[string]any)
+	assert.Equal(t, len(paths), 2)
+	op := paths["/users/{id}/orders"].(map[string]any)["post"].(map[string]any)
+	assert.Equal(t, op["operationId"], "postUsersIdOrders")
+	assert.DeepEqual(t, op["parameters"], []any{
+		map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "integer", "format": "int64"}},
+		map[string]any{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer", "format": "int64"}},
+		map[string]any{"name": "tag", "in": "query", "schema": map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+		map[string]any{"name": "X-Tenant-Id", "in": "header", "schema": map[string]any{"type": "string"}},
+	})
+	assert.DeepEqual(t, op["requestBody"], map[string]any{
+		"required": true,
+		"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+			"type":     "object",
+			"required": []any{"item"},
+			"properties": map[string]any{
+				"item":     map[string]any{"type": "string"},
+				"quantity": map[string]any{"type": "integer", "format": "int64", "maximum": float64(10)},
+			},
+		}}},
+	})
+	responses := op["responses"].(map[string]any)
+	assert.DeepEqual(t, responses["201"], map[string]any{
+		"description": "Created",
+		"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/createOrderResponse"}}},
+	})
+	assert.Assert(t, responses["400"] != nil && responses["default"] != nil)
+	assert.DeepEqual(t, responses["default"].(map[string]any)["content"], map[string]any{
+		"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+	})
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Assert(t, schemas["Error"] != nil)
+	assert.Equal(t, len(schemas["createOrderResponse"].(map[string]any)["properties"].(map[string]any)), 6)
+	op = paths["/files/{path}"].(map[string]any)["get"].(map[string]any)
+	assert.DeepEqual(t, op["responses"], map[string]any{"default": map[string]any{"description": "Response"}})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapi.DefaultDocsPath, nil))
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Assert(t, strings.Contains(w.Body.String(), `url: "/meta/openapi.json"`))
+	assert.Assert(t, strings.Contains(w.Body.String(), `src="/meta/docs/swagger-ui-bundle.js"`))
+	assert.Assert(t, !strings.Contains(w.Body.String(), "https://"))
+	for asset, contentType := range map[string]string{"swagger-ui-bundle.js": "text/javascript; charset=utf-8", "swagger-ui.css": "text/css; charset=utf-8"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapi.DefaultDocsPath+"/"+asset, nil))
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, w.Header().Get("Content-Type"), contentType)
+		assert.Assert(t, w.Body.Len() > 0)
+	}
+
+	out := &bytes.Buffer{}
+	ok, err := openapi.Command([]string{"serve"}, out, func() *openapi.Document { return router.OpenAPI(openapi.Info{}) })
+	assert.Assert(t, !ok)
+	assert.NilError(t, err)
+	ok, err = openapi.Command([]string{openapi.CommandName}, out, func() *openapi.Document { return router.OpenAPI(openapi.Info{Title: "Orders"}) })
+	assert.Assert(t, ok)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out.String(), "{\n  \"openapi\": \"3.1.0\""))
+
+	errors.SetErrorEncoder(base.NewProblemEncoder(""))
+	defer errors.SetErrorEncoder(errors.EnvelopeEncoder{})
+	problemDoc := router.OpenAPI(openapi.Info{})
+	response := problemDoc.Paths["/users/{id}/orders"].Post.Responses["default"]
+	assert.Equal(t, response.Content[errors.ContentTypeProblemJSON].Schema.Ref, "#/components/schemas/"+openapi.ProblemSchemaName)
+	assert.Equal(t, problemDoc.Components.Schemas[openapi.ProblemSchemaName].Properties["status"].Type, "integer")
+	assert.Assert(t, problemDoc.Components.Schemas[openapi.ErrorSchemaName] == nil)
+}
+
+func TestRouterGroups(t *testing.T) {
+	router, err := handler.New()
+	assert.NilError(t, err)
+	var calls []string
+	trace := func(name string) middleware.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	requireScope := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			metadata, _ := route.GetMetadata(r.Context())
+			if r.Header.Get("X-Scope") != metadata.Scope {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		metadata, _ := route.GetMetadata(r.Context())
+		pattern, _ := route.Pattern(r.Context())
+		w.Write([]byte(metadata.Name + " " + pattern))
+	}
+	router.HandlerFunc(http.MethodGet, "/health", ok, handler.WithName("health"))
+	admin := router.Group("/admin", trace("admin"), requireScope)
+	admin.HandlerFunc(http.MethodGet, "/users/:id", ok, handler.WithName("getUser"), handler.WithScope("admin"), handler.WithTags("users"))
+	reports := admin.Group("/reports")
+	reports.Use(trace("reports"))
+	reports.HandlerFunc(http.MethodGet, "/daily", ok, handler.WithScope("admin"), handler.WithMiddleware(trace("route")))
+	router.Use(trace("root"))
+
+	serve := func(path, scope string) *httptest.ResponseRecorder {
+		calls = nil
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Scope", scope)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w := serve("/health", "")
+	assert.Equal(t, w.Body.String(), "health /health")
+	assert.DeepEqual(t, calls, []string{"root"})
+	w = serve("/admin/users/1", "admin")
+	assert.Equal(t, w.Body.String(), "getUser /admin/users/:id")
+	assert.DeepEqual(t, calls, []string{"root", "admin"})
+	w = serve("/admin/users/1", "")
+	assert.Equal(t, w.Code, http.StatusForbidden)
+	w = serve("/admin/reports/daily", "admin")
+	assert.Equal(t, w.Body.String(), " /admin/reports/daily")
+	assert.DeepEqual(t, calls, []string{"root", "admin", "reports", "route"})
+	routes := router.Routes()
+	assert.Equal(t, len(routes), 3)
+	assert.Equal(t, routes[1].Path, "/admin/users/:id")
+	assert.DeepEqual(t, router.OpenAPI(openapi.Info{}).Paths["/admin/users/{id}"].Get.Tags, []string{"users"})
+	assert.Equal(t, router.OpenAPI(openapi.Info{}).Paths["/admin/users/{id}"].Get.OperationID, "getUser")
+}
+
+func TestProblemDetails(t *testing.T) {
+	errors.SetErrorEncoder(base.NewProblemEncoder("https://example.com/problems/"))
+	defer errors.SetErrorEncoder(errors.EnvelopeEncoder{})
+	srv := New(t)
+	req := httptest.NewRequest(http.MethodGet, "/error/errorUnauthorized", nil)
+	req.Header.Set(correlation.CorrelationIDHeader, "corr-1")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusForbidden)
+	assert.Equal(t, w.Header().Get("Content-Type"), errors.ContentTypeProblemJSON)
+	statusCode, contentType, _ := errorhandler.Handle(context.Background(), &errors.HTTPError{StatusCode: http.StatusConflict, Err: &errors.Error{Code: "CONFLICT"}})
+	assert.Equal(t, statusCode, http.StatusConflict)
+	assert.Equal(t, contentType, errors.ContentTypeProblemJSON)
+	assert.Equal(t, w.Body.String(), `{"instance":"corr-1","one":"two","status":403,"title":"display this","type":"https://example.com/problems/hello.new.custom.error"}`)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/service/abc/search", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	assert.Equal(t, w.Header().Get("Content-Type"), errors.ContentTypeProblemJSON)
+	assert.Equal(t, w.Body.String(), `{"path":"/service/abc/search","status":404,"title":"URL Not Found","type":"https://example.com/problems/URL_NOT_FOUND"}`)
+}
@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sabariramc/go-kit/app/base"
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/kafka/consumer"
+	"github.com/sabariramc/go-kit/log/correlation"
+	"github.com/segmentio/kafka-go"
+)
+
+// BatchHandler handles a batch of messages from a single topic.
+type BatchHandler interface {
+	HandleBatch(context.Context, []*kafka.Message) error
+}
+
+type BatchHandlerFunc func(context.Context, []*kafka.Message) error
+
+func (f BatchHandlerFunc) HandleBatch(ctx context.Context, msgs []*kafka.Message) error {
+	return f(ctx, msgs)
+}
+
+// AddBatchHandler registers a batch handler for the topic, batching must be enabled through WithBatch.
+// Topics without a batch handler are handled message by message through their Handler.
+func (k *KafkaConsumer) AddBatchHandler(ctx context.Context, topicName string, handler BatchHandler) {
+	if handler == nil {
+		k.log.Panic(ctx).Err(fmt.Errorf("KafkaConsumer.AddBatchHandler: handler parameter cannot be nil")).Msg("missing batch handler for topic - " + topicName)
+	}
+	if k.batchSize == 0 {
+		k.log.Panic(ctx).Err(fmt.Errorf("KafkaConsumer.AddBatchHandler: batching not enabled")).Msg("batching not enabled for topic - " + topicName)
+	}
+	if _, ok := k.batchHandler[topicName]; ok {
+		k.log.Panic(ctx).Err(fmt.Errorf("KafkaConsumer.AddBatchHandler: handler for topic exist")).Msg("duplicate batch handler for topic - " + topicName)
+	}
+	if _, ok := k.topics[topicName]; !ok {
+		k.log.Panic(ctx).Err(fmt.Errorf("KafkaConsumer.AddBatchHandler: topic not subscribed")).Msg("topic not subscribed - " + topicName)
+	}
+	k.batchHandler[topicName] = handler
+}
+
+// HandleBatch runs the batch handler of the topic for the batch, retrying and forwarding every message of the batch
+// as per the failure policy on error. Topics without a batch handler are handled message by message. Like Handle, it
+// returns nil once every message of the batch is done with, otherwise the batch must not be acknowledged.
+func (k *KafkaConsumer) HandleBatch(ctx context.Context, batch *consumer.Batch) error {
+	if len(batch.Messages) == 0 {
+		return nil
+	}
+	handler := k.getBatchHandler(batch)
+	if handler == nil {
+		for _, msg := range batch.Messages {
+			if err := k.Handle(msg.Ctx, msg.Message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	msgs := make([]*kafka.Message, 0, len(batch.Messages))
+	for _, msg := range batch.Messages {
+		if !k.isDuplicate(msg.Ctx, msg.Message) {
+			msgs = append(msgs, msg.Message)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	var sp span.Span
+	var statusCode = http.StatusOK
+	var err error
+	if k.tr != nil {
+		sp, ctx = k.startBatchSpan(ctx, batch)
+		defer func() {
+			if err != nil {
+				sp.SetError(err, "")
+				statusCode, _, _ = base.ProcessError(ctx, err)
+			}
+			sp.SetStatus(statusCode, http.StatusText(statusCode))
+			sp.Finish()
+		}()
+	}
+	var attempts uint
+	start := time.Now()
+	attempts, err = k.process(ctx, func(ctx context.Context) error {
+		return handler.HandleBatch(ctx, msgs)
+	})
+	k.metrics.recordHandler(ctx, k.metrics.batchHandlerDuration, batch.Topic, start, err)
+	if err != nil {
+		k.log.Error(ctx).Err(err).Uint("attempts", attempts).Int("batchSize", len(msgs)).Msg("Error in processing kafka batch")
+		for _, msg := range msgs {
+			if fwdErr := k.forward(ctx, msg, attempts, err); fwdErr != nil {
+				k.log.Error(ctx).Err(fwdErr).Msg("Error in forwarding failed kafka message")
+				return fmt.Errorf("KafkaConsumer.HandleBatch: %w", fwdErr)
+			}
+		}
+		return nil
+	}
+	for _, msg := range msgs {
+		k.markProcessed(ctx, msg)
+	}
+	return nil
+}
+
+// getBatchHandler returns the batch handler for the topic of the batch, batches from retry and dead-letter topics fall back to the handler of the original topic.
+func (k *KafkaConsumer) getBatchHandler(batch *consumer.Batch) BatchHandler {
+	if handler, ok := k.batchHandler[batch.Topic]; ok {
+		return handler
+	}
+	return k.batchHandler[OriginalTopic(batch.Messages[0].Message)]
+}
+
+// consumeBatches handles batches until the consumer is stopped or polling ends, acknowledging each batch once handled.
+// It stops at the first batch that is not done with, leaving it unacknowledged.
+func (k *KafkaConsumer) consumeBatches(stopCtx context.Context, cancelPoll context.CancelFunc) {
+	for {
+		select {
+		case <-stopCtx.Done():
+			cancelPoll()
+			return
+		case batch, ok := <-k.batchCh:
+			if !ok {
+				return
+			}
+			if err := k.HandleBatch(batch.Ctx, batch); err != nil {
+				k.failed(stopCtx, err)
+				cancelPoll()
+				return
+			}
+			k.AckBatch(batch)
+		}
+	}
+}
+
+func (k *KafkaConsumer) startBatchSpan(ctx context.Context, batch *consumer.Batch) (span.Span, context.Context) {
+	corr, _ := correlation.ExtractCorrelationParam(ctx)
+	ctx, sp := k.tr.NewSpanFromContext(ctx, "kafka.consume.batch", span.SpanKindConsumer, batch.Topic)
+	sp.SetAttribute("correlationId", corr.CorrelationID)
+	sp.SetAttribute(span.MessageSystem, "kafka")
+	sp.SetAttribute("messaging.kafka.topic", batch.Topic)
+	sp.SetAttribute("messaging.batch.message_count", len(batch.Messages))
+	return sp, ctx
+}
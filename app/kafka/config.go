@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sabariramc/go-kit/app/base"
 	span "github.com/sabariramc/go-kit/instrumentation"
@@ -17,11 +18,21 @@ type Tracer interface {
 }
 
 type Config struct {
-	Reader             *consumer.Reader
+	Reader             *consumer.Reader // Reader must be in CommitOnAck mode, NewConfig creates one when it is not set.
 	Base               *base.Base
 	Log                *log.Logger
 	Tracer             Tracer
 	MessageChannelSize int
+	FailurePolicy      FailurePolicy
+	Concurrency        int                 // Concurrency is the number of workers processing messages, 1 processes messages sequentially.
+	MaxInFlight        int                 // MaxInFlight is the maximum number of messages dispatched to workers and not yet completed.
+	Ordering           Ordering            // Ordering is the unit within which message order is preserved when Concurrency is more than 1.
+	BatchSize          int                 // BatchSize is the maximum number of messages in a batch, zero disables batching.
+	BatchLinger        time.Duration       // BatchLinger is the maximum time a batch waits for more messages after its first message.
+	DedupStore         consumer.DedupStore // DedupStore, when set, skips messages whose idempotency key was already processed.
+	Meter              span.Meter          // Meter, when set, records handler latency and is passed to the reader created by NewConfig.
+	MaxLag             int64               // MaxLag fails the health check when the lag of any partition exceeds it, zero disables the check.
+	MaxCommitAge       time.Duration       // MaxCommitAge fails the health check when a received message stays uncommitted longer than it, zero disables the check.
 }
 
 func NewConfig(opt ...Options) (*Config, error) {
@@ -29,6 +40,10 @@ func NewConfig(opt ...Options) (*Config, error) {
 		Base:               base.New(),
 		Log:                log.New("KafkaConsumer"),
 		MessageChannelSize: 1,
+		FailurePolicy:      NewDefaultFailurePolicy(),
+		Concurrency:        1,
+		MaxInFlight:        1,
+		Ordering:           OrderByPartition,
 	}
 	for _, o := range opt {
 		if err := o(cfg); err != nil {
@@ -36,7 +51,11 @@ func NewConfig(opt ...Options) (*Config, error) {
 		}
 	}
 	if cfg.Reader == nil {
-		consumer, err := consumer.New(context.TODO())
+		readerOpt := []consumer.Options{consumer.WithCommitMode(consumer.CommitOnAck)}
+		if cfg.Meter != nil {
+			readerOpt = append(readerOpt, consumer.WithMeter(cfg.Meter))
+		}
+		consumer, err := consumer.New(context.TODO(), readerOpt...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
 		}
@@ -58,7 +77,81 @@ func ValidateConfig(cfg *Config) error {
 	if cfg.Log == nil {
 		return fmt.Errorf("logger is not configured")
 	}
-	return nil
+	if cfg.MaxLag < 0 || cfg.MaxCommitAge < 0 {
+		return fmt.Errorf("health thresholds must not be negative")
+	}
+	if cfg.Reader.CommitMode() != consumer.CommitOnAck {
+		return fmt.Errorf("kafka reader commit mode must be %v so that failed messages are not committed", consumer.CommitOnAck)
+	}
+	if cfg.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if cfg.Concurrency > 1 && cfg.MaxInFlight < cfg.Concurrency {
+		return fmt.Errorf("max in-flight must not be less than concurrency")
+	}
+	if cfg.BatchSize > 0 {
+		if cfg.BatchLinger <= 0 {
+			return fmt.Errorf("batch linger must be positive when batching is enabled")
+		}
+		if cfg.Concurrency > 1 {
+			return fmt.Errorf("batching cannot be combined with concurrency")
+		}
+	}
+	return ValidateFailurePolicy(cfg.FailurePolicy)
 }
 
 type Options func(*Config) error
+
+// WithConcurrency processes messages with the given number of workers, preserving order within the ordering unit.
+// At most maxInFlight messages are dispatched to the workers at any time.
+func WithConcurrency(workers, maxInFlight int, ordering Ordering) Options {
+	return func(c *Config) error {
+		c.Concurrency = workers
+		c.MaxInFlight = maxInFlight
+		c.Ordering = ordering
+		return nil
+	}
+}
+
+// WithBatch groups messages of a topic into batches of up to size messages, waiting at most linger after the first message of a batch.
+func WithBatch(size int, linger time.Duration) Options {
+	return func(c *Config) error {
+		c.BatchSize = size
+		c.BatchLinger = linger
+		return nil
+	}
+}
+
+// WithDedupStore skips messages whose idempotency key is already recorded in the store and records the key once a message is processed.
+func WithDedupStore(store consumer.DedupStore) Options {
+	return func(c *Config) error {
+		c.DedupStore = store
+		return nil
+	}
+}
+
+// WithMeter records handler latency to the meter, the reader created by NewConfig records its metrics to the same meter.
+func WithMeter(meter span.Meter) Options {
+	return func(c *Config) error {
+		c.Meter = meter
+		return nil
+	}
+}
+
+// WithHealthThresholds fails the health check when the lag of a partition exceeds maxLag or a received message stays
+// uncommitted longer than maxCommitAge, a zero value disables the respective check.
+func WithHealthThresholds(maxLag int64, maxCommitAge time.Duration) Options {
+	return func(c *Config) error {
+		c.MaxLag = maxLag
+		c.MaxCommitAge = maxCommitAge
+		return nil
+	}
+}
+
+// WithFailurePolicy sets the policy applied when a handler returns an error or panics.
+func WithFailurePolicy(policy FailurePolicy) Options {
+	return func(c *Config) error {
+		c.FailurePolicy = policy
+		return nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/sabariramc/go-kit/app/base"
 	"github.com/sabariramc/go-kit/kafka/consumer"
@@ -26,29 +27,53 @@ func (f HandlerFunc) Handle(ctx context.Context, msg *kafka.Message) error {
 type KafkaConsumer struct {
 	*consumer.Reader
 	*base.Base
-	log        *log.Logger
-	ch         chan *consumer.MessageWithContext
-	handler    map[string]Handler
-	tr         Tracer
-	stop       context.CancelFunc
-	shutdownWG sync.WaitGroup
-	topics     map[string]struct{}
+	log          *log.Logger
+	ch           chan *consumer.MessageWithContext
+	handler      map[string]Handler
+	tr           Tracer
+	stop         context.CancelFunc
+	shutdownWG   sync.WaitGroup
+	topics       map[string]struct{}
+	failure      FailurePolicy
+	concurrency  int
+	maxInFlight  int
+	ordering     Ordering
+	batchCh      chan *consumer.Batch
+	batchSize    int
+	batchLinger  time.Duration
+	batchHandler map[string]BatchHandler
+	dedup        consumer.DedupStore
+	metrics      *consumerMetrics
+	maxLag       int64
+	maxCommitAge time.Duration
 }
 
 // New creates a new instance of kafka.
 func New(option ...Options) (*KafkaConsumer, error) {
-	cfg, err := NewConfig()
+	cfg, err := NewConfig(option...)
 	if err != nil {
 		return nil, err
 	}
 	k := &KafkaConsumer{
-		Reader:  cfg.Reader,
-		Base:    cfg.Base,
-		log:     cfg.Log,
-		handler: make(map[string]Handler),
-		tr:      cfg.Tracer,
-		ch:      make(chan *consumer.MessageWithContext, cfg.MessageChannelSize),
-		topics:  make(map[string]struct{}),
+		Reader:       cfg.Reader,
+		Base:         cfg.Base,
+		log:          cfg.Log,
+		handler:      make(map[string]Handler),
+		tr:           cfg.Tracer,
+		ch:           make(chan *consumer.MessageWithContext, cfg.MessageChannelSize),
+		topics:       make(map[string]struct{}),
+		failure:      cfg.FailurePolicy,
+		concurrency:  cfg.Concurrency,
+		maxInFlight:  cfg.MaxInFlight,
+		ordering:     cfg.Ordering,
+		batchCh:      make(chan *consumer.Batch, cfg.MessageChannelSize),
+		batchSize:    cfg.BatchSize,
+		batchLinger:  cfg.BatchLinger,
+		batchHandler: make(map[string]BatchHandler),
+		dedup:        cfg.DedupStore,
+		metrics:      newConsumerMetrics(cfg.Meter),
+		maxLag:       cfg.MaxLag,
+		maxCommitAge: cfg.MaxCommitAge,
 	}
 	for _, val := range k.GetTopics() {
 		k.topics[val] = struct{}{}
@@ -58,6 +83,11 @@ func New(option ...Options) (*KafkaConsumer, error) {
 	return k, nil
 }
 
+// failed logs a message that was neither handled nor forwarded, the consumer stops without acknowledging it.
+func (k *KafkaConsumer) failed(ctx context.Context, err error) {
+	k.log.Error(ctx).Err(err).Msg("kafka message not processed, stopping consumer without committing it")
+}
+
 func (k *KafkaConsumer) Close(ctx context.Context) error {
 	k.stop()
 	k.shutdownWG.Wait()
@@ -81,7 +111,13 @@ func (k *KafkaConsumer) Start(ctx context.Context) {
 	pollCtx, cancelPoll := context.WithCancel(correlation.GetContextWithCorrelationParam(context.Background(), corr))
 	go func() {
 		defer pollWg.Done()
-		offset, err := k.Poll(pollCtx, k.ch)
+		var offset consumer.OffsetMap
+		var err error
+		if k.batchSize > 0 {
+			offset, err = k.PollBatch(pollCtx, k.batchCh, k.batchSize, k.batchLinger)
+		} else {
+			offset, err = k.Poll(pollCtx, k.ch)
+		}
 		if err != nil && !errors.Is(err, context.Canceled) {
 			k.log.Error(stopCtx).Err(err).Object("offsets", offset).Msg("Kafka consumer exited")
 		}
@@ -89,16 +125,39 @@ func (k *KafkaConsumer) Start(ctx context.Context) {
 	}()
 	k.log.Info(stopCtx).Msg("kafka consumer started")
 	defer k.log.Info(stopCtx).Msg("kafka consumer stopped")
+	if k.batchSize > 0 {
+		k.consumeBatches(stopCtx, cancelPoll)
+		return
+	}
+	var pool *workerPool
+	var poolFailed <-chan struct{}
+	if k.concurrency > 1 {
+		pool = k.newWorkerPool(stopCtx, k.concurrency, k.maxInFlight, k.ordering)
+		poolFailed = pool.failed
+		defer pool.close()
+	}
 	for {
 		select {
 		case <-stopCtx.Done():
 			cancelPoll()
 			return
+		case <-poolFailed:
+			cancelPoll()
+			return
 		case msg, ok := <-k.ch:
 			if !ok {
 				return
 			}
-			k.Handle(msg.Ctx, msg.Message)
+			if pool == nil {
+				if err := k.Process(msg); err != nil {
+					k.failed(stopCtx, err)
+					cancelPoll()
+					return
+				}
+			} else if !pool.dispatch(stopCtx, msg) {
+				cancelPoll()
+				return
+			}
 		}
 	}
 }
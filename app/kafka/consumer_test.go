@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	consumer "github.com/sabariramc/go-kit/app/kafka"
 	ck "github.com/sabariramc/go-kit/kafka"
+	reader "github.com/sabariramc/go-kit/kafka/consumer"
 	"github.com/sabariramc/go-kit/kafka/producer"
 	"github.com/segmentio/kafka-go"
 	"gotest.tools/v3/assert"
@@ -88,3 +90,60 @@ func TestKafkaConsumerContext(t *testing.T) {
 	kc.RegisterHooks(pr)
 	kc.Start(timerCtx)
 }
+
+func TestKafkaConsumerConcurrency(t *testing.T) {
+	r, err := reader.New(context.TODO(), func(c *reader.Config) error {
+		c.ReaderConfig.GroupTopics = []string{TopicThree}
+		return nil
+	}, reader.WithGroupID("TestKafkaConsumerConcurrency"), reader.WithCommitMode(reader.CommitOnAck))
+	assert.NilError(t, err)
+	kc, err := consumer.New(func(c *consumer.Config) error {
+		c.Reader = r
+		return nil
+	}, consumer.WithConcurrency(4, 16, consumer.OrderByKey))
+	assert.NilError(t, err)
+	prefix := "TestKafkaConsumerConcurrency" + uuid.NewString()
+	keys, perKey := 5, 20
+	var lock sync.Mutex
+	received := make(map[string][]int)
+	count := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		key := string(msg.Key)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		val, _ := strconv.Atoi(string(msg.Value))
+		time.Sleep(time.Millisecond * time.Duration(val%3))
+		lock.Lock()
+		defer lock.Unlock()
+		received[key] = append(received[key], val)
+		count++
+		if count == keys*perKey {
+			cancel()
+		}
+		return nil
+	}))
+	pr, err := producer.New(context.TODO())
+	assert.NilError(t, err)
+	go func() {
+		for i := 0; i < perKey; i++ {
+			for j := 0; j < keys; j++ {
+				pr.WriteMessages(context.Background(), kafka.Message{
+					Topic: TopicThree,
+					Key:   []byte(prefix + strconv.Itoa(j)),
+					Value: []byte(strconv.Itoa(i)),
+				})
+			}
+		}
+	}()
+	kc.RegisterHooks(pr)
+	kc.Start(ctx)
+	assert.Equal(t, len(received), keys)
+	for key, values := range received {
+		assert.Equal(t, len(values), perKey, key)
+		for i, val := range values {
+			assert.Equal(t, val, i, key)
+		}
+	}
+}
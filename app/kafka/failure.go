@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"context"
+	e "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sabariramc/go-kit/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers set on messages published to retry and dead-letter topics.
+const (
+	HeaderOriginalTopic     = "X-Original-Topic"
+	HeaderOriginalPartition = "X-Original-Partition"
+	HeaderOriginalOffset    = "X-Original-Offset"
+	HeaderErrorCode         = "X-Error-Code"
+	HeaderAttemptCount      = "X-Attempt-Count"
+	HeaderFirstFailureTime  = "X-First-Failure-Time"
+)
+
+// Suffixes used to derive retry and dead-letter topic names from the original topic.
+const (
+	RetryTopicSuffix      = ".retry."
+	DeadLetterTopicSuffix = ".dlq"
+)
+
+// Writer publishes messages to Kafka, it is satisfied by *producer.Writer.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// FailurePolicy configures how KafkaConsumer handles a message whose handler returns an error or panics.
+//
+// The handler is attempted MaxAttempts times in-process with exponential backoff. If it still fails the message
+// is published to <topic>.retry.1 ... <topic>.retry.<RetryTopics> in turn and finally to <topic>.dlq when DeadLetter is set.
+// A message that still fails with no topic left, or that cannot be published, is not acknowledged and stops the
+// consumer, so that its offset is not committed and it is consumed again on restart.
+type FailurePolicy struct {
+	MaxAttempts  uint          // MaxAttempts is the number of in-process attempts for a message, including the first one.
+	MinRetryWait time.Duration // MinRetryWait is the minimum duration to wait before an in-process retry.
+	MaxRetryWait time.Duration // MaxRetryWait is the maximum duration to wait before an in-process retry.
+	RetryTopics  uint          // RetryTopics is the number of retry topics in the chain, zero disables retry topics.
+	DeadLetter   bool          // DeadLetter publishes the message to <topic>.dlq once the retry topics are exhausted.
+	Writer       Writer        // Writer is used to publish to retry and dead-letter topics.
+}
+
+// NewDefaultFailurePolicy returns a FailurePolicy that attempts a message once and does not forward it, so that a
+// failed message stops the consumer.
+func NewDefaultFailurePolicy() FailurePolicy {
+	return FailurePolicy{
+		MaxAttempts:  1,
+		MinRetryWait: 100 * time.Millisecond,
+		MaxRetryWait: 5 * time.Second,
+	}
+}
+
+// ValidateFailurePolicy validates the failure policy.
+func ValidateFailurePolicy(p FailurePolicy) error {
+	if p.MaxAttempts == 0 {
+		return fmt.Errorf("failure policy: MaxAttempts must be at least 1")
+	}
+	if p.MinRetryWait > p.MaxRetryWait {
+		return fmt.Errorf("failure policy: MinRetryWait must not exceed MaxRetryWait")
+	}
+	if (p.RetryTopics > 0 || p.DeadLetter) && p.Writer == nil {
+		return fmt.Errorf("failure policy: Writer is required for retry or dead-letter topics")
+	}
+	return nil
+}
+
+// backoff returns the wait duration before the given in-process attempt.
+func (p FailurePolicy) backoff(attempt uint) time.Duration {
+	wait := p.MinRetryWait
+	for i := uint(1); i < attempt && wait < p.MaxRetryWait; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxRetryWait {
+		wait = p.MaxRetryWait
+	}
+	return wait
+}
+
+// nextTopic returns the topic a failed message should be published to, or an empty string if there is none left.
+func (p FailurePolicy) nextTopic(original, current string) string {
+	stage := uint(0)
+	if n, ok := strings.CutPrefix(current, original+RetryTopicSuffix); ok {
+		if v, err := strconv.ParseUint(n, 10, 64); err == nil {
+			stage = uint(v)
+		}
+	} else if current == original+DeadLetterTopicSuffix {
+		return ""
+	}
+	if stage < p.RetryTopics {
+		return RetryTopic(original, stage+1)
+	}
+	if p.DeadLetter {
+		return DeadLetterTopic(original)
+	}
+	return ""
+}
+
+// RetryTopic returns the name of the n-th retry topic for the topic.
+func RetryTopic(topic string, n uint) string {
+	return topic + RetryTopicSuffix + strconv.FormatUint(uint64(n), 10)
+}
+
+// DeadLetterTopic returns the name of the dead-letter topic for the topic.
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterTopicSuffix
+}
+
+// OriginalTopic returns the topic the message was first consumed from, using the HeaderOriginalTopic header when present.
+func OriginalTopic(msg *kafka.Message) string {
+	if topic, ok := getHeader(msg, HeaderOriginalTopic); ok {
+		return topic
+	}
+	return msg.Topic
+}
+
+// ErrorCode returns the code of the errors.Error or errors.HTTPError wrapped in err, or an empty string.
+func ErrorCode(err error) string {
+	var httpErr *errors.HTTPError
+	var custErr *errors.Error
+	if e.As(err, &httpErr) && httpErr.Err != nil {
+		return httpErr.Err.Code
+	} else if e.As(err, &custErr) {
+		return custErr.Code
+	}
+	return ""
+}
+
+// forward publishes the failed message to the next retry or dead-letter topic, it returns an error when the policy
+// has no topic left to publish to.
+func (k *KafkaConsumer) forward(ctx context.Context, msg *kafka.Message, attempts uint, err error) error {
+	topic := k.failure.nextTopic(OriginalTopic(msg), msg.Topic)
+	if topic == "" {
+		return fmt.Errorf("KafkaConsumer.forward: no retry or dead-letter topic after %v: %w", msg.Topic, err)
+	}
+	return k.forwardTo(ctx, msg, topic, attempts, err)
+}
+
+// forwardTo publishes the failed message to the topic with headers tracing it back to the original message.
+func (k *KafkaConsumer) forwardTo(ctx context.Context, msg *kafka.Message, topic string, attempts uint, err error) error {
+	original := OriginalTopic(msg)
+	fwd := kafka.Message{
+		Topic:   topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: make([]kafka.Header, 0, len(msg.Headers)+6),
+	}
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset, HeaderErrorCode, HeaderAttemptCount, HeaderFirstFailureTime:
+		default:
+			fwd.Headers = append(fwd.Headers, h)
+		}
+	}
+	partition, ok := getHeader(msg, HeaderOriginalPartition)
+	if !ok {
+		partition = strconv.Itoa(msg.Partition)
+	}
+	offset, ok := getHeader(msg, HeaderOriginalOffset)
+	if !ok {
+		offset = strconv.FormatInt(msg.Offset, 10)
+	}
+	firstFailure, ok := getHeader(msg, HeaderFirstFailureTime)
+	if !ok {
+		firstFailure = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if prev, ok := getHeader(msg, HeaderAttemptCount); ok {
+		if v, err := strconv.ParseUint(prev, 10, 64); err == nil {
+			attempts += uint(v)
+		}
+	}
+	fwd.Headers = append(fwd.Headers,
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(original)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(partition)},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(offset)},
+		kafka.Header{Key: HeaderErrorCode, Value: []byte(ErrorCode(err))},
+		kafka.Header{Key: HeaderAttemptCount, Value: []byte(strconv.FormatUint(uint64(attempts), 10))},
+		kafka.Header{Key: HeaderFirstFailureTime, Value: []byte(firstFailure)},
+	)
+	if err := k.failure.Writer.WriteMessages(ctx, fwd); err != nil {
+		return fmt.Errorf("KafkaConsumer.forward: error publishing to %v: %w", topic, err)
+	}
+	k.log.Warn(ctx).Str("topic", topic).Uint("attempts", attempts).Msg("failed kafka message forwarded")
+	return nil
+}
+
+func getHeader(msg *kafka.Message, key string) (string, bool) {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
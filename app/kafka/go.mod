@@ -4,22 +4,39 @@ go 1.24.5
 
 require (
 	github.com/google/uuid v1.6.0
-	github.com/sabariramc/go-kit/app/base v1.0.3
+	github.com/sabariramc/go-kit/app/base v1.0.4
 	github.com/sabariramc/go-kit/env v1.0.0
-	github.com/sabariramc/go-kit/instrumentation v1.0.1
-	github.com/sabariramc/go-kit/kafka v1.0.2
+	github.com/sabariramc/go-kit/errors v1.0.2
+	github.com/sabariramc/go-kit/instrumentation v1.0.2
+	github.com/sabariramc/go-kit/kafka v1.0.3
 	github.com/sabariramc/go-kit/log v1.3.2
+	github.com/sabariramc/go-kit/validate v1.0.0
 	github.com/segmentio/kafka-go v0.4.48
 	gotest.tools/v3 v3.5.2
 )
 
 require (
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
-	github.com/klauspost/compress v1.17.8 // indirect
+	github.com/hamba/avro/v2 v2.31.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/compress v1.18.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pierrec/lz4/v4 v4.1.21 // indirect
 	github.com/rs/zerolog v1.34.0 // indirect
-	github.com/sabariramc/go-kit/errors v1.0.1 // indirect
 	golang.org/x/sys v0.20.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
 )
+
+replace github.com/sabariramc/go-kit/instrumentation => ../../instrumentation
+
+replace github.com/sabariramc/go-kit/errors => ../../errors
+
+replace github.com/sabariramc/go-kit/app/base => ../base
+
+replace github.com/sabariramc/go-kit/validate => ../../validate
+
+replace github.com/sabariramc/go-kit/kafka => ../../kafka
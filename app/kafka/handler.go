@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"time"
 
 	"github.com/sabariramc/go-kit/app/base"
 	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/kafka/consumer"
 	"github.com/sabariramc/go-kit/log/correlation"
 	"github.com/segmentio/kafka-go"
 )
@@ -25,14 +27,28 @@ func (k *KafkaConsumer) AddHandler(ctx context.Context, topicName string, handle
 	k.handler[topicName] = handler
 }
 
-func (k *KafkaConsumer) Handle(ctx context.Context, msg *kafka.Message) {
+// Handle runs the handler of the topic for the message, retrying and forwarding it as per the failure policy on error.
+// It returns nil once the message is done with: handled, skipped as a duplicate or forwarded to a retry or dead-letter
+// topic. Otherwise the message must not be acknowledged, so that its offset is not committed. Messages of a topic
+// without handler are logged and skipped, or forwarded straight to the dead-letter topic when it is enabled.
+func (k *KafkaConsumer) Handle(ctx context.Context, msg *kafka.Message) error {
 	var span span.Span
 	var statusCode = http.StatusOK
 	var err error
-	topicName := msg.Topic
-	handler := k.handler[topicName]
+	handler := k.getHandler(msg)
 	if handler == nil {
-		k.log.Error(ctx).Msg("missing handler for topic - " + topicName)
+		k.log.Error(ctx).Msg("missing handler for topic - " + msg.Topic)
+		if !k.failure.DeadLetter {
+			return nil
+		}
+		err = fmt.Errorf("KafkaConsumer.Handle: missing handler for topic %v", msg.Topic)
+		if fwdErr := k.forwardTo(ctx, msg, DeadLetterTopic(OriginalTopic(msg)), 0, err); fwdErr != nil {
+			return fmt.Errorf("KafkaConsumer.Handle: %w", fwdErr)
+		}
+		return nil
+	}
+	if k.isDuplicate(ctx, msg) {
+		return nil
 	}
 	if k.tr != nil {
 		span, ctx = k.startSpan(ctx, msg)
@@ -40,13 +56,104 @@ func (k *KafkaConsumer) Handle(ctx context.Context, msg *kafka.Message) {
 			defer func() {
 				if err != nil {
 					span.SetError(err, "")
-					statusCode, _ = base.ProcessError(ctx, err)
+					statusCode, _, _ = base.ProcessError(ctx, err)
 				}
 				span.SetStatus(statusCode, http.StatusText(statusCode))
 				span.Finish()
 			}()
 		}
 	}
+	var attempts uint
+	start := time.Now()
+	attempts, err = k.process(ctx, func(ctx context.Context) error {
+		return handler.Handle(ctx, msg)
+	})
+	k.metrics.recordHandler(ctx, k.metrics.handlerDuration, msg.Topic, start, err)
+	if err != nil {
+		k.log.Error(ctx).Err(err).Uint("attempts", attempts).Msg("Error in processing kafka message")
+		if fwdErr := k.forward(ctx, msg, attempts, err); fwdErr != nil {
+			k.log.Error(ctx).Err(fwdErr).Msg("Error in forwarding failed kafka message")
+			return fmt.Errorf("KafkaConsumer.Handle: %w", fwdErr)
+		}
+		return nil
+	}
+	k.markProcessed(ctx, msg)
+	return nil
+}
+
+// Process handles the message and acknowledges it to the reader when Handle returns nil.
+func (k *KafkaConsumer) Process(msg *consumer.MessageWithContext) error {
+	if err := k.Handle(msg.Ctx, msg.Message); err != nil {
+		return err
+	}
+	k.Ack(msg.Message)
+	return nil
+}
+
+// isDuplicate reports whether the idempotency key of the message is already in the dedup store.
+func (k *KafkaConsumer) isDuplicate(ctx context.Context, msg *kafka.Message) bool {
+	if k.dedup == nil {
+		return false
+	}
+	key, ok := consumer.IdempotencyKey(msg)
+	if !ok {
+		return false
+	}
+	seen, err := k.dedup.Exists(ctx, key)
+	if err != nil {
+		k.log.Error(ctx).Err(err).Msg("Error in checking dedup store, processing message")
+		return false
+	}
+	if seen {
+		k.log.Info(ctx).Str("idempotencyKey", key).Msg("duplicate kafka message skipped")
+	}
+	return seen
+}
+
+// markProcessed records the idempotency key of the message in the dedup store.
+func (k *KafkaConsumer) markProcessed(ctx context.Context, msg *kafka.Message) {
+	if k.dedup == nil {
+		return
+	}
+	key, ok := consumer.IdempotencyKey(msg)
+	if !ok {
+		return
+	}
+	if err := k.dedup.Add(ctx, key); err != nil {
+		k.log.Error(ctx).Err(err).Msg("Error in updating dedup store")
+	}
+}
+
+// getHandler returns the handler for the topic of the message, messages from retry and dead-letter topics fall back to the handler of the original topic.
+func (k *KafkaConsumer) getHandler(msg *kafka.Message) Handler {
+	if handler, ok := k.handler[msg.Topic]; ok {
+		return handler
+	}
+	return k.handler[OriginalTopic(msg)]
+}
+
+// process runs fn, retrying in-process with backoff as per the failure policy.
+func (k *KafkaConsumer) process(ctx context.Context, fn func(context.Context) error) (attempt uint, err error) {
+	for {
+		attempt++
+		err = k.invoke(ctx, fn)
+		if err == nil || attempt >= k.failure.MaxAttempts {
+			return
+		}
+		wait := k.failure.backoff(attempt)
+		k.log.Warn(ctx).Err(err).Msgf("kafka message processing failed - retry %v of %v in %vms", attempt, k.failure.MaxAttempts-1, wait.Milliseconds())
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// invoke runs fn, recovering from panics.
+func (k *KafkaConsumer) invoke(ctx context.Context, fn func(context.Context) error) (err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
 			stackTrace := string(debug.Stack())
@@ -58,10 +165,7 @@ func (k *KafkaConsumer) Handle(ctx context.Context, msg *kafka.Message) {
 			}
 		}
 	}()
-	err = handler.Handle(ctx, msg)
-	if err != nil {
-		k.log.Error(ctx).Err(err).Msg("Error in processing kafka message")
-	}
+	return fn(ctx)
 }
 
 func (k *KafkaConsumer) startSpan(ctx context.Context, msg *kafka.Message) (span.Span, context.Context) {
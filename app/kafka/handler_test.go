@@ -0,0 +1,310 @@
+package kafka_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	consumer "github.com/sabariramc/go-kit/app/kafka"
+	"github.com/sabariramc/go-kit/errors"
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/instrumentation/memory"
+	ck "github.com/sabariramc/go-kit/kafka"
+	"github.com/sabariramc/go-kit/kafka/codec"
+	reader "github.com/sabariramc/go-kit/kafka/consumer"
+	"github.com/sabariramc/go-kit/validate"
+	"github.com/segmentio/kafka-go"
+	"gotest.tools/v3/assert"
+)
+
+type memoryWriter struct {
+	lock sync.Mutex
+	msgs []kafka.Message
+}
+
+func (w *memoryWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *memoryWriter) last() kafka.Message {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.msgs[len(w.msgs)-1]
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestKafkaConsumerFailurePolicy(t *testing.T) {
+	writer := &memoryWriter{}
+	kc, err := consumer.New(consumer.WithFailurePolicy(consumer.FailurePolicy{
+		MaxAttempts:  3,
+		MinRetryWait: time.Millisecond,
+		MaxRetryWait: 5 * time.Millisecond,
+		RetryTopics:  2,
+		DeadLetter:   true,
+		Writer:       writer,
+	}))
+	assert.NilError(t, err)
+	calls := 0
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		calls++
+		if string(msg.Value) == "panic" {
+			panic("handler panic")
+		}
+		return &errors.HTTPError{StatusCode: 422, Err: &errors.Error{Code: "INVALID_EVENT", Message: "invalid event"}}
+	}))
+	msg := &kafka.Message{Topic: TopicThree, Partition: 3, Offset: 42, Key: []byte("key"), Value: []byte("value")}
+	assert.NilError(t, kc.Handle(context.Background(), msg))
+	assert.Equal(t, calls, 3)
+	fwd := writer.last()
+	assert.Equal(t, fwd.Topic, consumer.RetryTopic(TopicThree, 1))
+	assert.Equal(t, string(fwd.Value), "value")
+	assert.Equal(t, header(fwd, consumer.HeaderOriginalTopic), TopicThree)
+	assert.Equal(t, header(fwd, consumer.HeaderOriginalPartition), "3")
+	assert.Equal(t, header(fwd, consumer.HeaderOriginalOffset), "42")
+	assert.Equal(t, header(fwd, consumer.HeaderErrorCode), "INVALID_EVENT")
+	assert.Equal(t, header(fwd, consumer.HeaderAttemptCount), "3")
+	firstFailure := header(fwd, consumer.HeaderFirstFailureTime)
+	_, err = time.Parse(time.RFC3339Nano, firstFailure)
+	assert.NilError(t, err)
+	for i, topic := range []string{consumer.RetryTopic(TopicThree, 2), consumer.DeadLetterTopic(TopicThree)} {
+		fwd.Partition, fwd.Offset = 0, int64(i)
+		assert.NilError(t, kc.Handle(context.Background(), &fwd))
+		fwd = writer.last()
+		assert.Equal(t, fwd.Topic, topic)
+		assert.Equal(t, header(fwd, consumer.HeaderOriginalTopic), TopicThree)
+		assert.Equal(t, header(fwd, consumer.HeaderOriginalOffset), "42")
+		assert.Equal(t, header(fwd, consumer.HeaderAttemptCount), strconv.Itoa((i+2)*3))
+		assert.Equal(t, header(fwd, consumer.HeaderFirstFailureTime), firstFailure)
+	}
+	count := len(writer.msgs)
+	assert.ErrorContains(t, kc.Handle(context.Background(), &fwd), "no retry or dead-letter topic")
+	assert.Equal(t, len(writer.msgs), count)
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("panic")})
+	fwd = writer.last()
+	assert.Equal(t, fwd.Topic, consumer.RetryTopic(TopicThree, 1))
+	assert.Equal(t, header(fwd, consumer.HeaderErrorCode), "")
+}
+
+func TestKafkaConsumerFailurePolicyRecovered(t *testing.T) {
+	writer := &memoryWriter{}
+	kc, err := consumer.New(consumer.WithFailurePolicy(consumer.FailurePolicy{
+		MaxAttempts:  3,
+		MinRetryWait: time.Millisecond,
+		MaxRetryWait: 5 * time.Millisecond,
+		DeadLetter:   true,
+		Writer:       writer,
+	}))
+	assert.NilError(t, err)
+	calls := 0
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		calls++
+		if calls < 3 {
+			return &errors.Error{Code: "TEMPORARY", Message: "temporary failure"}
+		}
+		return nil
+	}))
+	assert.NilError(t, kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("value")}))
+	assert.Equal(t, calls, 3)
+	assert.Equal(t, len(writer.msgs), 0)
+}
+
+func TestKafkaConsumerHandleBatch(t *testing.T) {
+	writer := &memoryWriter{}
+	kc, err := consumer.New(consumer.WithBatch(10, time.Second), consumer.WithFailurePolicy(consumer.FailurePolicy{
+		MaxAttempts:  2,
+		MinRetryWait: time.Millisecond,
+		MaxRetryWait: 5 * time.Millisecond,
+		DeadLetter:   true,
+		Writer:       writer,
+	}))
+	assert.NilError(t, err)
+	batches := 0
+	fail := false
+	kc.AddBatchHandler(context.Background(), TopicThree, consumer.BatchHandlerFunc(func(ctx context.Context, msgs []*kafka.Message) error {
+		batches++
+		assert.Equal(t, len(msgs), 3)
+		if fail {
+			return &errors.Error{Code: "BULK_INSERT_FAILED", Message: "bulk insert failed"}
+		}
+		return nil
+	}))
+	messages := 0
+	kc.AddHandler(context.Background(), TopicOne, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		messages++
+		return nil
+	}))
+	newBatch := func(topic string) *reader.Batch {
+		batch := &reader.Batch{Topic: topic, Ctx: context.Background()}
+		for i := 0; i < 3; i++ {
+			batch.Messages = append(batch.Messages, &reader.MessageWithContext{
+				Message: &kafka.Message{Topic: topic, Offset: int64(i), Value: []byte(strconv.Itoa(i))},
+				Ctx:     context.Background(),
+			})
+		}
+		return batch
+	}
+	assert.NilError(t, kc.HandleBatch(context.Background(), newBatch(TopicThree)))
+	assert.Equal(t, batches, 1)
+	assert.Equal(t, len(writer.msgs), 0)
+	assert.NilError(t, kc.HandleBatch(context.Background(), newBatch(TopicOne)))
+	assert.Equal(t, messages, 3)
+	fail = true
+	assert.NilError(t, kc.HandleBatch(context.Background(), newBatch(TopicThree)))
+	assert.Equal(t, batches, 3)
+	assert.Equal(t, len(writer.msgs), 3)
+	for i, msg := range writer.msgs {
+		assert.Equal(t, msg.Topic, consumer.DeadLetterTopic(TopicThree))
+		assert.Equal(t, header(msg, consumer.HeaderOriginalOffset), strconv.Itoa(i))
+		assert.Equal(t, header(msg, consumer.HeaderErrorCode), "BULK_INSERT_FAILED")
+		assert.Equal(t, header(msg, consumer.HeaderAttemptCount), "2")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return fmt.Errorf("broker unavailable")
+}
+
+func TestKafkaConsumerFailurePolicyUnforwarded(t *testing.T) {
+	r, err := reader.New(context.TODO(), func(c *reader.Config) error {
+		c.ReaderConfig.GroupTopics = []string{TopicThree}
+		return nil
+	}, reader.WithGroupID("TestKafkaConsumerFailurePolicyUnforwarded"), reader.WithCommitMode(reader.CommitOnAck))
+	assert.NilError(t, err)
+	kc, err := consumer.New(func(c *consumer.Config) error {
+		c.Reader = r
+		return nil
+	}, consumer.WithFailurePolicy(consumer.FailurePolicy{
+		MaxAttempts: 1,
+		DeadLetter:  true,
+		Writer:      failingWriter{},
+	}))
+	assert.NilError(t, err)
+	calls := 0
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		calls++
+		if string(msg.Value) == "fail" {
+			return &errors.Error{Code: "FAILED", Message: "failed"}
+		}
+		return nil
+	}))
+	msgs := []*reader.MessageWithContext{
+		{Message: &kafka.Message{Topic: TopicThree, Offset: 1, Value: []byte("ok")}, Ctx: context.Background()},
+		{Message: &kafka.Message{Topic: TopicThree, Offset: 2, Value: []byte("fail")}, Ctx: context.Background()},
+		{Message: &kafka.Message{Topic: TopicThree, Offset: 3, Value: []byte("ok")}, Ctx: context.Background()},
+	}
+	for _, msg := range msgs {
+		r.TrackOffset(msg.Message)
+	}
+	assert.NilError(t, kc.Process(msgs[0]))
+	assert.ErrorContains(t, kc.Process(msgs[1]), "broker unavailable")
+	assert.NilError(t, kc.Process(msgs[2]))
+	_, consumed := r.GetOffsets()
+	assert.DeepEqual(t, consumed, reader.OffsetMap{{Topic: TopicThree, Partition: 0}: 1})
+	assert.ErrorContains(t, kc.Process(&reader.MessageWithContext{Message: &kafka.Message{Topic: TopicOne}, Ctx: context.Background()}), "broker unavailable")
+	assert.Equal(t, calls, 3)
+	kc, err = consumer.New(func(c *consumer.Config) error {
+		c.Reader = r
+		return nil
+	})
+	assert.NilError(t, err)
+	unhandled := &reader.MessageWithContext{Message: &kafka.Message{Topic: TopicOne, Offset: 4}, Ctx: context.Background()}
+	r.TrackOffset(unhandled.Message)
+	assert.NilError(t, kc.Process(unhandled))
+	_, consumed = r.GetOffsets()
+	assert.DeepEqual(t, consumed, reader.OffsetMap{{Topic: TopicThree, Partition: 0}: 1, {Topic: TopicOne, Partition: 0}: 4})
+	_, err = consumer.New(func(c *consumer.Config) error {
+		c.Reader, err = reader.New(context.TODO(), reader.WithCommitMode(reader.CommitOnReceive))
+		return err
+	})
+	assert.ErrorContains(t, err, "commit mode")
+}
+
+func TestKafkaConsumerDedup(t *testing.T) {
+	kc, err := consumer.New(consumer.WithDedupStore(reader.NewMemoryDedupStore(10)))
+	assert.NilError(t, err)
+	calls := 0
+	fail := true
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		calls++
+		if fail {
+			return &errors.Error{Code: "TEMPORARY", Message: "temporary failure"}
+		}
+		return nil
+	}))
+	msg := &kafka.Message{Topic: TopicThree, Value: []byte("value"), Headers: []kafka.Header{{Key: ck.HeaderIdempotencyKey, Value: []byte("key-1")}}}
+	assert.ErrorContains(t, kc.Handle(context.Background(), msg), "no retry or dead-letter topic")
+	fail = false
+	assert.NilError(t, kc.Handle(context.Background(), msg))
+	assert.NilError(t, kc.Handle(context.Background(), msg))
+	assert.Equal(t, calls, 2)
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("value")})
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("value")})
+	assert.Equal(t, calls, 4)
+}
+
+type typedEvent struct {
+	ID string `json:"id" validate:"required"`
+}
+
+func TestKafkaConsumerTypedHandler(t *testing.T) {
+	writer := &memoryWriter{}
+	policy := consumer.NewDefaultFailurePolicy()
+	policy.DeadLetter = true
+	policy.Writer = writer
+	kc, err := consumer.New(consumer.WithFailurePolicy(policy))
+	assert.NilError(t, err)
+	registry := codec.NewMemoryRegistry()
+	c := codec.NewJSONCodec(registry, `{"type":"object"}`, nil)
+	var got []string
+	kc.AddHandler(context.Background(), TopicThree, consumer.NewTypedHandler(c, func(ctx context.Context, msg *kafka.Message, v typedEvent) error {
+		got = append(got, v.ID)
+		return nil
+	}))
+	value, err := c.Encode(context.Background(), TopicThree, typedEvent{ID: "event-1"})
+	assert.NilError(t, err)
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: value})
+	assert.DeepEqual(t, got, []string{"event-1"})
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte(`{"id":"event-2"}`)})
+	assert.DeepEqual(t, got, []string{"event-1"})
+	assert.Equal(t, header(writer.last(), consumer.HeaderErrorCode), consumer.ErrorCodeDecode)
+	value, err = c.Encode(context.Background(), TopicThree, typedEvent{})
+	assert.NilError(t, err)
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: value})
+	assert.DeepEqual(t, got, []string{"event-1"})
+	assert.Equal(t, header(writer.last(), consumer.HeaderErrorCode), validate.ErrorCodeValidation)
+}
+
+func TestKafkaConsumerMetrics(t *testing.T) {
+	meter := memory.NewMeter()
+	kc, err := consumer.New(consumer.WithMeter(meter), consumer.WithHealthThresholds(100, time.Minute))
+	assert.NilError(t, err)
+	kc.AddHandler(context.Background(), TopicThree, consumer.HandlerFunc(func(ctx context.Context, msg *kafka.Message) error {
+		if string(msg.Value) == "fail" {
+			return &errors.Error{Code: "FAILED", Message: "failed"}
+		}
+		return nil
+	}))
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("ok")})
+	kc.Handle(context.Background(), &kafka.Message{Topic: TopicThree, Value: []byte("fail")})
+	assert.Equal(t, meter.Count(consumer.MetricHandlerDuration, span.Attr(span.MessagingDestinationName, TopicThree)), 2)
+	assert.Equal(t, meter.Count(consumer.MetricHandlerDuration, span.Attr("status", consumer.StatusError)), 1)
+	assert.NilError(t, kc.HealthCheck(context.Background()))
+	_, err = consumer.New(consumer.WithHealthThresholds(-1, 0))
+	assert.ErrorContains(t, err, "health thresholds")
+}
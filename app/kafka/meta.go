@@ -2,9 +2,22 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 )
 
+// HealthCheck fails when the lag of a partition or the age of the oldest uncommitted message exceeds the configured thresholds.
 func (k *KafkaConsumer) HealthCheck(ctx context.Context) error {
-	k.Reader.Stats()
+	if k.maxLag > 0 {
+		for partition, lag := range k.Reader.Lag() {
+			if lag > k.maxLag {
+				return fmt.Errorf("KafkaConsumer.HealthCheck: lag of %v:%v is %v, exceeds %v", partition.Topic, partition.Partition, lag, k.maxLag)
+			}
+		}
+	}
+	if k.maxCommitAge > 0 {
+		if age := k.Reader.CommitAge(); age > k.maxCommitAge {
+			return fmt.Errorf("KafkaConsumer.HealthCheck: messages uncommitted for %v, exceeds %v", age, k.maxCommitAge)
+		}
+	}
 	return nil
 }
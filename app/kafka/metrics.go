@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Metric names recorded by KafkaConsumer, the Reader records the lag, throughput, commit and rebalance metrics.
+const (
+	MetricHandlerDuration      = "kafka.consumer.handler.duration"
+	MetricBatchHandlerDuration = "kafka.consumer.batch.handler.duration"
+)
+
+// Values of the status attribute of handler metrics.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+type consumerMetrics struct {
+	handlerDuration      span.Histogram
+	batchHandlerDuration span.Histogram
+}
+
+func newConsumerMetrics(meter span.Meter) *consumerMetrics {
+	if meter == nil {
+		meter = span.NoopMeter{}
+	}
+	return &consumerMetrics{
+		handlerDuration:      meter.Histogram(MetricHandlerDuration, span.UnitSeconds, "Duration of message handling including in-process retries"),
+		batchHandlerDuration: meter.Histogram(MetricBatchHandlerDuration, span.UnitSeconds, "Duration of batch handling including in-process retries"),
+	}
+}
+
+// recordHandler records the duration of handling a message or batch of the topic.
+func (m *consumerMetrics) recordHandler(ctx context.Context, h span.Histogram, topic string, start time.Time, err error) {
+	status := StatusSuccess
+	if err != nil {
+		status = StatusError
+	}
+	h.Record(ctx, time.Since(start).Seconds(), span.Attr(span.MessagingDestinationName, topic), span.Attr("status", status))
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/sabariramc/go-kit/errors"
+	"github.com/sabariramc/go-kit/kafka/codec"
+	"github.com/sabariramc/go-kit/validate"
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrorCodeDecode is the error code returned by a TypedHandler when a message cannot be decoded.
+const ErrorCodeDecode = "KAFKA_DECODE_ERROR"
+
+// TypedHandlerFunc handles a message with its decoded value.
+type TypedHandlerFunc[T any] func(ctx context.Context, msg *kafka.Message, v T) error
+
+// TypedHandler is a Handler that decodes the message value with a codec and validates it with validate.Default before
+// calling the handler function. Invalid values fail with a validate.ErrorCodeValidation error listing the violations.
+type TypedHandler[T any] struct {
+	codec     codec.Codec
+	fn        TypedHandlerFunc[T]
+	validator *validate.Validator
+}
+
+func NewTypedHandler[T any](c codec.Codec, fn TypedHandlerFunc[T]) *TypedHandler[T] {
+	return &TypedHandler[T]{codec: c, fn: fn, validator: validate.Default}
+}
+
+// WithValidator sets the validator of the decoded values, nil disables validation.
+func (h *TypedHandler[T]) WithValidator(v *validate.Validator) *TypedHandler[T] {
+	h.validator = v
+	return h
+}
+
+func (h *TypedHandler[T]) Handle(ctx context.Context, msg *kafka.Message) error {
+	var v T
+	if err := h.codec.Decode(ctx, OriginalTopic(msg), msg.Value, &v); err != nil {
+		return &errors.Error{Code: ErrorCodeDecode, Message: "error decoding message value", Description: err.Error()}
+	}
+	if h.validator != nil {
+		if err := h.validator.Validate(&v); err != nil {
+			return err
+		}
+	}
+	return h.fn(ctx, msg, v)
+}
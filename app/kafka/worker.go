@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/sabariramc/go-kit/kafka/consumer"
+)
+
+// Ordering defines the unit within which KafkaConsumer preserves message order when processing concurrently.
+type Ordering int
+
+const (
+	OrderByPartition Ordering = iota // OrderByPartition processes messages of a partition in order.
+	OrderByKey                       // OrderByKey processes messages with the same key in order, messages without a key fall back to their partition.
+)
+
+// workerPool dispatches messages to a fixed set of workers so that messages with the same ordering key are handled by the same worker.
+type workerPool struct {
+	workers  []chan *consumer.MessageWithContext
+	inFlight chan struct{}
+	ordering Ordering
+	wg       sync.WaitGroup
+	failed   chan struct{} // failed is closed when a message is not processed.
+	fail     sync.Once
+}
+
+// newWorkerPool starts the workers, each message is handled and then acknowledged to the reader. Once a message is
+// not processed the pool is failed and the queued messages are released without being handled or acknowledged.
+func (k *KafkaConsumer) newWorkerPool(ctx context.Context, workers, maxInFlight int, ordering Ordering) *workerPool {
+	p := &workerPool{
+		workers:  make([]chan *consumer.MessageWithContext, workers),
+		inFlight: make(chan struct{}, maxInFlight),
+		ordering: ordering,
+		failed:   make(chan struct{}),
+	}
+	p.wg.Add(workers)
+	for i := range p.workers {
+		ch := make(chan *consumer.MessageWithContext, maxInFlight)
+		p.workers[i] = ch
+		go func() {
+			defer p.wg.Done()
+			for msg := range ch {
+				select {
+				case <-p.failed:
+				default:
+					if err := k.Process(msg); err != nil {
+						p.fail.Do(func() {
+							k.failed(ctx, err)
+							close(p.failed)
+						})
+					}
+				}
+				<-p.inFlight
+			}
+		}()
+	}
+	return p
+}
+
+// dispatch blocks until the number of in-flight messages is below the limit and queues the message to its worker.
+func (p *workerPool) dispatch(ctx context.Context, msg *consumer.MessageWithContext) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case p.inFlight <- struct{}{}:
+	}
+	p.workers[p.worker(msg)] <- msg
+	return true
+}
+
+// worker returns the index of the worker responsible for the message.
+func (p *workerPool) worker(msg *consumer.MessageWithContext) int {
+	h := fnv.New32a()
+	if p.ordering == OrderByKey && len(msg.Key) > 0 {
+		h.Write(msg.Key)
+	} else {
+		h.Write([]byte(msg.Topic))
+		h.Write([]byte(strconv.Itoa(msg.Partition)))
+	}
+	return int(h.Sum32() % uint32(len(p.workers)))
+}
+
+// close stops accepting messages and waits for the in-flight messages to complete.
+func (p *workerPool) close() {
+	for _, ch := range p.workers {
+		close(ch)
+	}
+	p.wg.Wait()
+}
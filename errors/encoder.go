@@ -0,0 +1,144 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Content types of the encoded errors.
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeProblemJSON = "application/problem+json"
+)
+
+// ErrorEncoder encodes an error into the status code, the content type and the body of an HTTP response.
+type ErrorEncoder interface {
+	Encode(ctx context.Context, err error) (statusCode int, contentType string, body []byte)
+}
+
+var encoder atomic.Pointer[ErrorEncoder]
+
+func init() {
+	SetErrorEncoder(EnvelopeEncoder{})
+}
+
+// SetErrorEncoder sets the encoder used by EncodeError, EnvelopeEncoder by default.
+func SetErrorEncoder(enc ErrorEncoder) {
+	encoder.Store(&enc)
+}
+
+// EncodeError encodes the error with the encoder set by SetErrorEncoder.
+func EncodeError(ctx context.Context, err error) (int, string, []byte) {
+	return (*encoder.Load()).Encode(ctx, err)
+}
+
+// unknownError is the error encoded for errors that are neither an Error nor an HTTPError.
+var unknownError = &Error{
+	Code:    "INTERNAL_SERVER_ERROR",
+	Message: "Unknown error",
+}
+
+// resolve returns the status code and the Error of err, 500 for errors that are not an HTTPError. An HTTPError without
+// an Error is resolved to an Error derived from its status code.
+func resolve(err error) (int, *Error) {
+	var httpErr *HTTPError
+	var custErr *Error
+	if errors.As(err, &httpErr) {
+		if httpErr.Err != nil {
+			return httpErr.StatusCode, httpErr.Err
+		}
+		return httpErr.StatusCode, statusError(httpErr.StatusCode)
+	} else if errors.As(err, &custErr) {
+		return http.StatusInternalServerError, custErr
+	}
+	return http.StatusInternalServerError, unknownError
+}
+
+// statusError returns the Error of a status code, such as NOT_FOUND for 404.
+func statusError(statusCode int) *Error {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return unknownError
+	}
+	return &Error{
+		Code:    strings.ToUpper(strings.ReplaceAll(text, " ", "_")),
+		Message: text,
+	}
+}
+
+// EnvelopeEncoder encodes errors as {"error": {"code": ..., "message": ..., "description": ...}}.
+type EnvelopeEncoder struct{}
+
+func (EnvelopeEncoder) Encode(ctx context.Context, err error) (int, string, []byte) {
+	statusCode, custErr := resolve(err)
+	body, _ := custErr.MarshalJSON()
+	res := make([]byte, 0, len(body)+100)
+	res = append(res, []byte("{\"error\": ")...)
+	res = append(res, body...)
+	res = append(res, []byte("}")...)
+	return statusCode, ContentTypeJSON, res
+}
+
+// ProblemEncoder encodes errors as RFC 9457 problem details. Code is the type, Message the title and the keys of a
+// map Description are extension members, other descriptions are sent in the description member.
+type ProblemEncoder struct {
+	TypeBaseURI string                           // TypeBaseURI is prepended to the code to form the type.
+	Instance    func(ctx context.Context) string // Instance returns the instance of the problem, it is omitted when nil or empty.
+}
+
+// problemMembers are the members defined by RFC 9457, extensions with these names are dropped.
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+func (p *ProblemEncoder) Encode(ctx context.Context, err error) (int, string, []byte) {
+	statusCode, custErr := resolve(err)
+	problem := map[string]any{
+		"type":   p.TypeBaseURI + custErr.Code,
+		"title":  custErr.Message,
+		"status": statusCode,
+	}
+	if custErr.Code == "" {
+		problem["type"] = "about:blank"
+	}
+	if p.Instance != nil {
+		if instance := p.Instance(ctx); instance != "" {
+			problem["instance"] = instance
+		}
+	}
+	if custErr.Description != nil {
+		extensions, ok := descriptionMembers(custErr.Description)
+		if !ok {
+			extensions = map[string]any{"description": custErr.Description}
+		}
+		for k, v := range extensions {
+			if !problemMembers[k] {
+				problem[k] = v
+			}
+		}
+	}
+	body, mErr := json.Marshal(problem)
+	if mErr != nil {
+		problem = map[string]any{"type": p.TypeBaseURI + custErr.Code, "title": custErr.Message, "status": statusCode}
+		body, _ = json.Marshal(problem)
+	}
+	return statusCode, ContentTypeProblemJSON, body
+}
+
+// descriptionMembers returns the members of a description that encodes to a JSON object.
+func descriptionMembers(description any) (map[string]any, bool) {
+	if m, ok := description.(map[string]any); ok {
+		return m, true
+	}
+	blob, err := json.Marshal(description)
+	if err != nil || len(blob) == 0 || blob[0] != '{' {
+		return nil, false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(blob, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
@@ -1,10 +1,13 @@
 package errors_test
 
 import (
+	"context"
+	e "errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/sabariramc/go-kit/errors"
 	"github.com/sabariramc/go-kit/errors/internal"
 	"gotest.tools/v3/assert"
 )
@@ -16,20 +19,51 @@ func TestWriteError(t *testing.T) {
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 	assert.Equal(t, w.Code, http.StatusBadRequest)
-	assert.Equal(t, w.Body.String(), `{"code":"BAD_REQUEST","message":"Invalid input"}`)
+	assert.Equal(t, w.Body.String(), `{"error": {"code":"BAD_REQUEST","message":"Invalid input"}}`)
 	assert.Equal(t, w.Header().Get("Content-Type"), "application/json")
 	req, err = http.NewRequest("GET", "/custom-error", nil)
 	assert.NilError(t, err)
 	w = httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 	assert.Equal(t, w.Code, http.StatusInternalServerError)
-	assert.Equal(t, w.Body.String(), `{"code":"CUSTOM_ERROR","message":"Custom error occurred","description":"description"}`)
+	assert.Equal(t, w.Body.String(), `{"error": {"code":"CUSTOM_ERROR","message":"Custom error occurred","description":"description"}}`)
 	assert.Equal(t, w.Header().Get("Content-Type"), "application/json")
 	req, err = http.NewRequest("GET", "/internal-error", nil)
 	assert.NilError(t, err)
 	w = httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 	assert.Equal(t, w.Code, http.StatusInternalServerError)
-	assert.Equal(t, w.Body.String(), `{"code":"CUSTOM_ERROR","message":"Custom error occurred","description":{"nextStep":"check input"}}`)
+	assert.Equal(t, w.Body.String(), `{"error": {"code":"CUSTOM_ERROR","message":"Custom error occurred","description":{"nextStep":"check input"}}}`)
 	assert.Equal(t, w.Header().Get("Content-Type"), "application/json")
 }
+
+func TestProblemEncoder(t *testing.T) {
+	errors.SetErrorEncoder(&errors.ProblemEncoder{
+		TypeBaseURI: "https://example.com/problems/",
+		Instance: func(ctx context.Context) string {
+			return "request-1"
+		},
+	})
+	defer errors.SetErrorEncoder(errors.EnvelopeEncoder{})
+	mux := internal.NewServer()
+	req, err := http.NewRequest("GET", "/internal-error", nil)
+	assert.NilError(t, err)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusInternalServerError)
+	assert.Equal(t, w.Header().Get("Content-Type"), errors.ContentTypeProblemJSON)
+	assert.Equal(t, w.Body.String(), `{"instance":"request-1","nextStep":"check input","status":500,"title":"Custom error occurred","type":"https://example.com/problems/CUSTOM_ERROR"}`)
+	statusCode, contentType, body := errors.EncodeError(context.Background(), &errors.HTTPError{
+		StatusCode: http.StatusNotFound,
+		Err:        &errors.Error{Code: "NOT_FOUND", Message: "Not found", Description: []string{"id"}},
+	})
+	assert.Equal(t, statusCode, http.StatusNotFound)
+	assert.Equal(t, contentType, errors.ContentTypeProblemJSON)
+	assert.Equal(t, string(body), `{"description":["id"],"instance":"request-1","status":404,"title":"Not found","type":"https://example.com/problems/NOT_FOUND"}`)
+	statusCode, _, body = errors.EncodeError(context.Background(), &errors.HTTPError{StatusCode: http.StatusTooManyRequests})
+	assert.Equal(t, statusCode, http.StatusTooManyRequests)
+	assert.Equal(t, string(body), `{"instance":"request-1","status":429,"title":"Too Many Requests","type":"https://example.com/problems/TOO_MANY_REQUESTS"}`)
+	statusCode, _, body = errors.EncodeError(context.Background(), e.New("boom"))
+	assert.Equal(t, statusCode, http.StatusInternalServerError)
+	assert.Equal(t, string(body), `{"instance":"request-1","status":500,"title":"Unknown error","type":"https://example.com/problems/INTERNAL_SERVER_ERROR"}`)
+}
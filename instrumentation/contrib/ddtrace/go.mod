@@ -3,8 +3,9 @@ module github.com/sabariramc/go-kit/instrumentation/contrib/ddtrace
 go 1.24.4
 
 require (
+	github.com/DataDog/datadog-go/v5 v5.6.0
 	github.com/DataDog/dd-trace-go/v2 v2.1.0
-	github.com/sabariramc/go-kit/instrumentation v1.0.0
+	github.com/sabariramc/go-kit/instrumentation v1.0.2
 	github.com/segmentio/kafka-go v0.4.48
 )
 
@@ -18,7 +19,6 @@ require (
 	github.com/DataDog/datadog-agent/pkg/util/log v0.66.1 // indirect
 	github.com/DataDog/datadog-agent/pkg/util/scrubber v0.66.1 // indirect
 	github.com/DataDog/datadog-agent/pkg/version v0.66.1 // indirect
-	github.com/DataDog/datadog-go/v5 v5.6.0 // indirect
 	github.com/DataDog/go-libddwaf/v4 v4.3.0 // indirect
 	github.com/DataDog/go-runtime-metrics-internal v0.0.4-0.20250603194815-7edb7c2ad56a // indirect
 	github.com/DataDog/go-sqllexer v0.1.6 // indirect
@@ -78,3 +78,5 @@ require (
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/sabariramc/go-kit/instrumentation => ../..
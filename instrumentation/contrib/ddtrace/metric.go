@@ -0,0 +1,74 @@
+package ddtrace
+
+import (
+	"context"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Meter is the implementation of instrumentation.Meter for DogStatsD.
+//
+// Units and descriptions are not supported by DogStatsD and are ignored, histograms are sent as distributions so that
+// percentiles are computed server side. Send errors are dropped as DogStatsD is fire and forget.
+type Meter struct {
+	client statsd.ClientInterface
+}
+
+// NewMeter creates a Meter that sends measurements through the DogStatsD client.
+func NewMeter(client statsd.ClientInterface) *Meter {
+	return &Meter{client: client}
+}
+
+// InitMeter creates a DogStatsD client for the agent address and returns a Meter using it,
+// an empty address resolves the agent from DD_DOGSTATSD_URL or DD_AGENT_HOST.
+func InitMeter(addr string, opt ...statsd.Option) (*Meter, error) {
+	client, err := statsd.New(addr, opt...)
+	if err != nil {
+		return nil, err
+	}
+	return NewMeter(client), nil
+}
+
+func (m *Meter) Counter(name, unit, description string) span.Counter {
+	return &instrument{client: m.client, name: name}
+}
+
+func (m *Meter) Histogram(name, unit, description string) span.Histogram {
+	return &instrument{client: m.client, name: name}
+}
+
+func (m *Meter) Gauge(name, unit, description string) span.Gauge {
+	return &gauge{instrument{client: m.client, name: name}}
+}
+
+// instrument sends counter and distribution measurements for a metric name.
+type instrument struct {
+	client statsd.ClientInterface
+	name   string
+}
+
+func (i *instrument) Add(ctx context.Context, value int64, attrs ...span.Attribute) {
+	_ = i.client.Count(i.name, value, tags(attrs), 1)
+}
+
+func (i *instrument) Record(ctx context.Context, value float64, attrs ...span.Attribute) {
+	_ = i.client.Distribution(i.name, value, tags(attrs), 1)
+}
+
+type gauge struct {
+	instrument
+}
+
+func (g *gauge) Record(ctx context.Context, value float64, attrs ...span.Attribute) {
+	_ = g.client.Gauge(g.name, value, tags(attrs), 1)
+}
+
+// tags converts metric attributes to DogStatsD tags.
+func tags(attrs []span.Attribute) []string {
+	res := make([]string, len(attrs))
+	for i, a := range attrs {
+		res[i] = a.Key + ":" + a.Value
+	}
+	return res
+}
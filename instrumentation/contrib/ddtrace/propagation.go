@@ -0,0 +1,45 @@
+package ddtrace
+
+import (
+	"context"
+
+	ddtrace "github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// carrier adapts span.Carrier to the Datadog TextMapReader and TextMapWriter interfaces.
+type carrier struct {
+	span.Carrier
+}
+
+// ForeachKey iterates over every key in the carrier and invokes the handler for each key-value pair.
+func (c carrier) ForeachKey(handler func(key, val string) error) error {
+	for _, k := range c.Keys() {
+		if err := handler(k, c.Get(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteKey is the context key of a span context extracted from a carrier.
+type remoteKey struct{}
+
+// Inject injects the trace context of the active span in the context into the carrier, using the configured propagation styles.
+func (t *Tracer) Inject(ctx context.Context, c span.Carrier) {
+	sp, ok := ddtrace.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	_ = ddtrace.Inject(sp.Context(), carrier{c})
+}
+
+// Extract returns a context carrying the remote span context found in the carrier, the next span started from
+// the context through NewSpanFromContext becomes its child. The context is returned unchanged when extraction fails.
+func (t *Tracer) Extract(ctx context.Context, c span.Carrier) context.Context {
+	spanCtx, err := ddtrace.Extract(carrier{c})
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, spanCtx)
+}
@@ -16,6 +16,11 @@ func (t *Tracer) NewSpanFromContext(ctx context.Context, operationName string, k
 		ddtrace.ResourceName(resourceName),
 		ddtrace.Measured(),
 	}
+	if _, ok := ddtrace.SpanFromContext(ctx); !ok {
+		if spanCtx, ok := ctx.Value(remoteKey{}).(*ddtrace.SpanContext); ok {
+			opts = append(opts, ddtrace.ChildOf(spanCtx))
+		}
+	}
 	sp, ctx := ddtrace.StartSpanFromContext(ctx, operationName, opts...)
 	return ctx, &ddtraceSpan{Span: sp}
 }
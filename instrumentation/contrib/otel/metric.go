@@ -0,0 +1,83 @@
+package otel
+
+import (
+	"context"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Meter is the implementation of instrumentation.Meter backed by the global OpenTelemetry meter provider.
+type Meter struct {
+	meter metric.Meter
+}
+
+// NewMeter creates a Meter with the given instrumentation scope name, Init must be called first for the measurements to be exported.
+func NewMeter(name string) *Meter {
+	return &Meter{meter: otel.Meter(name)}
+}
+
+// Counter creates an Int64Counter, on error the error is passed to the global error handler and a no-op counter is returned.
+func (m *Meter) Counter(name, unit, description string) span.Counter {
+	c, err := m.meter.Int64Counter(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+		return span.NoopMeter{}.Counter(name, unit, description)
+	}
+	return &counter{c}
+}
+
+// Histogram creates a Float64Histogram, on error the error is passed to the global error handler and a no-op histogram is returned.
+func (m *Meter) Histogram(name, unit, description string) span.Histogram {
+	h, err := m.meter.Float64Histogram(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+		return span.NoopMeter{}.Histogram(name, unit, description)
+	}
+	return &histogram{h}
+}
+
+// Gauge creates a Float64Gauge, on error the error is passed to the global error handler and a no-op gauge is returned.
+func (m *Meter) Gauge(name, unit, description string) span.Gauge {
+	g, err := m.meter.Float64Gauge(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+		return span.NoopMeter{}.Gauge(name, unit, description)
+	}
+	return &gauge{g}
+}
+
+type counter struct {
+	metric.Int64Counter
+}
+
+func (c *counter) Add(ctx context.Context, value int64, attrs ...span.Attribute) {
+	c.Int64Counter.Add(ctx, value, metric.WithAttributes(attributes(attrs)...))
+}
+
+type histogram struct {
+	metric.Float64Histogram
+}
+
+func (h *histogram) Record(ctx context.Context, value float64, attrs ...span.Attribute) {
+	h.Float64Histogram.Record(ctx, value, metric.WithAttributes(attributes(attrs)...))
+}
+
+type gauge struct {
+	metric.Float64Gauge
+}
+
+func (g *gauge) Record(ctx context.Context, value float64, attrs ...span.Attribute) {
+	g.Float64Gauge.Record(ctx, value, metric.WithAttributes(attributes(attrs)...))
+}
+
+// attributes converts metric attributes to OpenTelemetry attributes.
+func attributes(attrs []span.Attribute) []attribute.KeyValue {
+	res := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		res[i] = attribute.String(a.Key, a.Value)
+	}
+	return res
+}
@@ -0,0 +1,18 @@
+package otel
+
+import (
+	"context"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"go.opentelemetry.io/otel"
+)
+
+// Inject injects the trace context and baggage of the context into the carrier using the global propagator.
+func (t *Tracer) Inject(ctx context.Context, carrier span.Carrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Extract returns a context carrying the remote trace context and baggage found in the carrier.
+func (t *Tracer) Extract(ctx context.Context, carrier span.Carrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
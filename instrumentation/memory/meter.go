@@ -0,0 +1,116 @@
+// Package memory is an in-memory implementation of instrumentation.Meter for tests.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Measurement is a value recorded by an instrument.
+type Measurement struct {
+	Value float64
+	Attrs []span.Attribute
+}
+
+// Matches reports whether the measurement carries every given attribute.
+func (m Measurement) Matches(attrs ...span.Attribute) bool {
+	for _, want := range attrs {
+		found := false
+		for _, a := range m.Attrs {
+			if a == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Meter records every measurement in memory by instrument name.
+type Meter struct {
+	mu           sync.Mutex
+	measurements map[string][]Measurement
+}
+
+func NewMeter() *Meter {
+	return &Meter{measurements: make(map[string][]Measurement)}
+}
+
+func (m *Meter) Counter(name, unit, description string) span.Counter {
+	return &instrument{meter: m, name: name}
+}
+
+func (m *Meter) Histogram(name, unit, description string) span.Histogram {
+	return &instrument{meter: m, name: name}
+}
+
+func (m *Meter) Gauge(name, unit, description string) span.Gauge {
+	return &instrument{meter: m, name: name}
+}
+
+func (m *Meter) record(name string, value float64, attrs []span.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.measurements[name] = append(m.measurements[name], Measurement{Value: value, Attrs: append([]span.Attribute(nil), attrs...)})
+}
+
+// Measurements returns the measurements of the instrument that carry every given attribute, in recording order.
+func (m *Meter) Measurements(name string, attrs ...span.Attribute) []Measurement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []Measurement
+	for _, v := range m.measurements[name] {
+		if v.Matches(attrs...) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Count returns the number of measurements of the instrument that carry every given attribute.
+func (m *Meter) Count(name string, attrs ...span.Attribute) int {
+	return len(m.Measurements(name, attrs...))
+}
+
+// Sum returns the sum of the measurements of the instrument that carry every given attribute.
+func (m *Meter) Sum(name string, attrs ...span.Attribute) float64 {
+	var sum float64
+	for _, v := range m.Measurements(name, attrs...) {
+		sum += v.Value
+	}
+	return sum
+}
+
+// Last returns the latest measurement of the instrument that carries every given attribute.
+func (m *Meter) Last(name string, attrs ...span.Attribute) (float64, bool) {
+	res := m.Measurements(name, attrs...)
+	if len(res) == 0 {
+		return 0, false
+	}
+	return res[len(res)-1].Value, true
+}
+
+// Reset discards all measurements.
+func (m *Meter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.measurements = make(map[string][]Measurement)
+}
+
+type instrument struct {
+	meter *Meter
+	name  string
+}
+
+func (i *instrument) Add(ctx context.Context, value int64, attrs ...span.Attribute) {
+	i.meter.record(i.name, float64(value), attrs)
+}
+
+func (i *instrument) Record(ctx context.Context, value float64, attrs ...span.Attribute) {
+	i.meter.record(i.name, value, attrs)
+}
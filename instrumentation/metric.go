@@ -0,0 +1,55 @@
+package span
+
+import "context"
+
+// Attribute is a key-value pair attached to a metric measurement.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr returns an Attribute with the given key and value.
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Counter records monotonically increasing values.
+type Counter interface {
+	Add(ctx context.Context, value int64, attrs ...Attribute)
+}
+
+// Histogram records a distribution of values.
+type Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Gauge records the current value of a measurement.
+type Gauge interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Meter creates metric instruments. Implementations must be safe for concurrent use and must return a usable instrument
+// even when the instrument cannot be registered with the backend.
+type Meter interface {
+	Counter(name, unit, description string) Counter
+	Histogram(name, unit, description string) Histogram
+	Gauge(name, unit, description string) Gauge
+}
+
+// NoopMeter is a Meter whose instruments discard all measurements.
+type NoopMeter struct{}
+
+func (NoopMeter) Counter(name, unit, description string) Counter     { return noopInstrument{} }
+func (NoopMeter) Histogram(name, unit, description string) Histogram { return noopInstrument{} }
+func (NoopMeter) Gauge(name, unit, description string) Gauge         { return noopInstrument{} }
+
+type noopInstrument struct{}
+
+func (noopInstrument) Add(ctx context.Context, value int64, attrs ...Attribute)      {}
+func (noopInstrument) Record(ctx context.Context, value float64, attrs ...Attribute) {}
+
+// Units used by metric instruments.
+const (
+	UnitSeconds = "s"
+	UnitCount   = "{count}"
+)
@@ -0,0 +1,42 @@
+package span
+
+import (
+	"context"
+	"net/http"
+)
+
+// Carrier is the storage medium used by a Propagator, its method set matches the OpenTelemetry TextMapCarrier.
+type Carrier interface {
+	Get(key string) string
+	Set(key string, value string)
+	Keys() []string
+}
+
+// Propagator injects the trace context of a context into a carrier and extracts a remote trace context from a carrier.
+// Tracers implement it alongside SpanOp, a span started from the context returned by Extract is a child of the remote span.
+type Propagator interface {
+	Inject(ctx context.Context, carrier Carrier)
+	Extract(ctx context.Context, carrier Carrier) context.Context
+}
+
+// HeaderCarrier adapts http.Header to the Carrier interface.
+type HeaderCarrier http.Header
+
+// Get returns the first value associated with the key.
+func (h HeaderCarrier) Get(key string) string {
+	return http.Header(h).Get(key)
+}
+
+// Set sets the value of the key, replacing any existing values.
+func (h HeaderCarrier) Set(key string, value string) {
+	http.Header(h).Set(key, value)
+}
+
+// Keys lists the keys stored in the carrier.
+func (h HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -26,8 +26,15 @@ const (
 	SpanKindInternal = "internal"
 )
 
-// Constants representing attribute keys for spans.
+// Constants representing attribute keys for spans and metrics.
 const (
-	HTTPStatusCode = "http.response.status_code"
-	MessageSystem  = "messaging.system"
+	HTTPStatusCode                  = "http.response.status_code"
+	HTTPRequestMethod               = "http.request.method"
+	HTTPRoute                       = "http.route"
+	ServerAddress                   = "server.address"
+	ErrorType                       = "error.type"
+	MessageSystem                   = "messaging.system"
+	MessagingDestinationName        = "messaging.destination.name"
+	MessagingDestinationPartitionID = "messaging.destination.partition.id"
+	MessagingConsumerGroupName      = "messaging.consumer.group.name"
 )
@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// AvroCodec encodes values with an Avro schema.
+//
+// When a registry is set, the schema is registered for the topic subject and values are written in the Confluent wire
+// format. Values are decoded with the writer schema looked up by the schema ID in the message.
+type AvroCodec struct {
+	parsed  avro.Schema
+	schema  *subjectSchema
+	writers sync.Map
+}
+
+// NewAvroCodec creates an AvroCodec for the schema, a nil registry encodes plain Avro binary.
+func NewAvroCodec(registry SchemaRegistry, schema string, subject SubjectNameStrategy) (*AvroCodec, error) {
+	parsed, err := avro.Parse(schema)
+	if err != nil {
+		return nil, fmt.Errorf("codec.NewAvroCodec: error parsing schema: %w", err)
+	}
+	return &AvroCodec{
+		parsed: parsed,
+		schema: newSubjectSchema(registry, Schema{Type: SchemaTypeAvro, Schema: parsed.String()}, subject),
+	}, nil
+}
+
+func (c *AvroCodec) Encode(ctx context.Context, topic string, v any) ([]byte, error) {
+	var buf []byte
+	if c.schema != nil {
+		id, err := c.schema.id(ctx, topic)
+		if err != nil {
+			return nil, fmt.Errorf("codec.AvroCodec.Encode: %w", err)
+		}
+		buf = AppendWireHeader(buf, id)
+	}
+	blob, err := avro.Marshal(c.parsed, v)
+	if err != nil {
+		return nil, fmt.Errorf("codec.AvroCodec.Encode: %w", err)
+	}
+	return append(buf, blob...), nil
+}
+
+func (c *AvroCodec) Decode(ctx context.Context, topic string, data []byte, v any) error {
+	schema := c.parsed
+	if c.schema != nil {
+		id, writer, payload, err := c.schema.lookup(ctx, data)
+		if err != nil {
+			return fmt.Errorf("codec.AvroCodec.Decode: %w", err)
+		}
+		schema, err = c.writerSchema(id, writer)
+		if err != nil {
+			return fmt.Errorf("codec.AvroCodec.Decode: %w", err)
+		}
+		data = payload
+	}
+	if err := avro.Unmarshal(schema, data, v); err != nil {
+		return fmt.Errorf("codec.AvroCodec.Decode: %w", err)
+	}
+	return nil
+}
+
+// writerSchema returns the parsed writer schema for the ID.
+func (c *AvroCodec) writerSchema(id int, schema Schema) (avro.Schema, error) {
+	if s, ok := c.writers.Load(id); ok {
+		return s.(avro.Schema), nil
+	}
+	parsed, err := avro.Parse(schema.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema %d: %w", id, err)
+	}
+	c.writers.Store(id, parsed)
+	return parsed, nil
+}
@@ -0,0 +1,98 @@
+// Package codec encodes and decodes Kafka message values, optionally in the Confluent wire format against a SchemaRegistry.
+//
+// The Confluent wire format prefixes the payload with a zero magic byte and the 4 byte big-endian schema ID:
+//
+//	| 0x00 | schema ID (4 bytes) | payload |
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// MagicByte is the first byte of a message in the Confluent wire format.
+const MagicByte byte = 0
+
+// wireHeaderSize is the size of the magic byte and the schema ID.
+const wireHeaderSize = 5
+
+// Codec encodes values into Kafka message values and decodes them back, topic is used to resolve the schema subject.
+type Codec interface {
+	Encode(ctx context.Context, topic string, v any) ([]byte, error)
+	Decode(ctx context.Context, topic string, data []byte, v any) error
+}
+
+// SubjectNameStrategy returns the schema registry subject for the value of a topic.
+type SubjectNameStrategy func(topic string) string
+
+// TopicNameStrategy is the default Confluent subject name strategy, <topic>-value.
+func TopicNameStrategy(topic string) string {
+	return topic + "-value"
+}
+
+// AppendWireHeader appends the magic byte and schema ID to b.
+func AppendWireHeader(b []byte, id int) []byte {
+	b = append(b, MagicByte)
+	return binary.BigEndian.AppendUint32(b, uint32(id))
+}
+
+// ParseWireHeader returns the schema ID and payload of a message in the Confluent wire format.
+func ParseWireHeader(data []byte) (int, []byte, error) {
+	if len(data) < wireHeaderSize {
+		return 0, nil, fmt.Errorf("codec.ParseWireHeader: message is shorter than the wire header")
+	}
+	if data[0] != MagicByte {
+		return 0, nil, fmt.Errorf("codec.ParseWireHeader: unknown magic byte %d", data[0])
+	}
+	return int(binary.BigEndian.Uint32(data[1:wireHeaderSize])), data[wireHeaderSize:], nil
+}
+
+// subjectSchema registers a schema under the subject of each topic and caches the schema ID.
+type subjectSchema struct {
+	registry SchemaRegistry
+	schema   Schema
+	subject  SubjectNameStrategy
+	ids      sync.Map
+}
+
+func newSubjectSchema(registry SchemaRegistry, schema Schema, subject SubjectNameStrategy) *subjectSchema {
+	if registry == nil {
+		return nil
+	}
+	if subject == nil {
+		subject = TopicNameStrategy
+	}
+	return &subjectSchema{registry: registry, schema: schema, subject: subject}
+}
+
+// id returns the schema ID registered for the topic, registering the schema on first use.
+func (s *subjectSchema) id(ctx context.Context, topic string) (int, error) {
+	subject := s.subject(topic)
+	if id, ok := s.ids.Load(subject); ok {
+		return id.(int), nil
+	}
+	id, err := s.registry.Register(ctx, subject, s.schema)
+	if err != nil {
+		return 0, fmt.Errorf("error registering schema for subject %s: %w", subject, err)
+	}
+	s.ids.Store(subject, id)
+	return id, nil
+}
+
+// lookup parses the wire header and returns the writer schema and the payload.
+func (s *subjectSchema) lookup(ctx context.Context, data []byte) (int, Schema, []byte, error) {
+	id, payload, err := ParseWireHeader(data)
+	if err != nil {
+		return 0, Schema{}, nil, err
+	}
+	schema, err := s.registry.GetByID(ctx, id)
+	if err != nil {
+		return 0, Schema{}, nil, fmt.Errorf("error fetching schema %d: %w", id, err)
+	}
+	if schema.Type != s.schema.Type {
+		return 0, Schema{}, nil, fmt.Errorf("schema %d is of type %s, expected %s", id, schema.Type, s.schema.Type)
+	}
+	return id, schema, payload, nil
+}
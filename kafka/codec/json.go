@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONCodec encodes values with encoding/json.
+//
+// When a registry is set, the JSON schema is registered for the topic subject and values are written in the Confluent wire format.
+// The codec does not validate values against the schema.
+type JSONCodec struct {
+	schema *subjectSchema
+}
+
+// NewJSONCodec creates a JSONCodec, a nil registry encodes plain JSON.
+func NewJSONCodec(registry SchemaRegistry, schema string, subject SubjectNameStrategy) *JSONCodec {
+	return &JSONCodec{schema: newSubjectSchema(registry, Schema{Type: SchemaTypeJSON, Schema: schema}, subject)}
+}
+
+func (c *JSONCodec) Encode(ctx context.Context, topic string, v any) ([]byte, error) {
+	var buf []byte
+	if c.schema != nil {
+		id, err := c.schema.id(ctx, topic)
+		if err != nil {
+			return nil, fmt.Errorf("codec.JSONCodec.Encode: %w", err)
+		}
+		buf = AppendWireHeader(buf, id)
+	}
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("codec.JSONCodec.Encode: %w", err)
+	}
+	return append(buf, blob...), nil
+}
+
+func (c *JSONCodec) Decode(ctx context.Context, topic string, data []byte, v any) error {
+	if c.schema != nil {
+		_, _, payload, err := c.schema.lookup(ctx, data)
+		if err != nil {
+			return fmt.Errorf("codec.JSONCodec.Decode: %w", err)
+		}
+		data = payload
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("codec.JSONCodec.Decode: %w", err)
+	}
+	return nil
+}
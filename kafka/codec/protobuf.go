@@ -0,0 +1,121 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtobufCodec encodes proto.Message values.
+//
+// When a registry is set, the .proto source in schema is registered for the topic subject and values are written in the
+// Confluent wire format, including the message index list that locates the message type within the schema.
+type ProtobufCodec struct {
+	schema *subjectSchema
+}
+
+// NewProtobufCodec creates a ProtobufCodec, a nil registry encodes plain protobuf.
+func NewProtobufCodec(registry SchemaRegistry, schema string, subject SubjectNameStrategy) *ProtobufCodec {
+	return &ProtobufCodec{schema: newSubjectSchema(registry, Schema{Type: SchemaTypeProtobuf, Schema: schema}, subject)}
+}
+
+func (c *ProtobufCodec) Encode(ctx context.Context, topic string, v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("codec.ProtobufCodec.Encode: %T is not a proto.Message", v)
+	}
+	var buf []byte
+	if c.schema != nil {
+		id, err := c.schema.id(ctx, topic)
+		if err != nil {
+			return nil, fmt.Errorf("codec.ProtobufCodec.Encode: %w", err)
+		}
+		buf = appendMessageIndexes(AppendWireHeader(buf, id), msg.ProtoReflect().Descriptor())
+	}
+	buf, err := proto.MarshalOptions{}.MarshalAppend(buf, msg)
+	if err != nil {
+		return nil, fmt.Errorf("codec.ProtobufCodec.Encode: %w", err)
+	}
+	return buf, nil
+}
+
+// Decode decodes into v, which must be a proto.Message or a pointer to one; a nil message pointer is allocated.
+func (c *ProtobufCodec) Decode(ctx context.Context, topic string, data []byte, v any) error {
+	msg, err := protoTarget(v)
+	if err != nil {
+		return fmt.Errorf("codec.ProtobufCodec.Decode: %w", err)
+	}
+	if c.schema != nil {
+		_, _, payload, err := c.schema.lookup(ctx, data)
+		if err != nil {
+			return fmt.Errorf("codec.ProtobufCodec.Decode: %w", err)
+		}
+		data, err = skipMessageIndexes(payload)
+		if err != nil {
+			return fmt.Errorf("codec.ProtobufCodec.Decode: %w", err)
+		}
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("codec.ProtobufCodec.Decode: %w", err)
+	}
+	return nil
+}
+
+// protoTarget returns the proto.Message to decode into.
+func protoTarget(v any) (proto.Message, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		if msg, ok := rv.Elem().Interface().(proto.Message); ok {
+			return msg, nil
+		}
+	}
+	if msg, ok := v.(proto.Message); ok && rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		return msg, nil
+	}
+	return nil, fmt.Errorf("%T is not a pointer to a proto.Message", v)
+}
+
+// appendMessageIndexes appends the path of the message within its file, the common case of the first top level message is written as a single zero.
+func appendMessageIndexes(b []byte, desc protoreflect.MessageDescriptor) []byte {
+	var indexes []int
+	var d protoreflect.Descriptor = desc
+	for {
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			break
+		}
+		indexes = append([]int{md.Index()}, indexes...)
+		d = md.Parent()
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	b = binary.AppendVarint(b, int64(len(indexes)))
+	for _, i := range indexes {
+		b = binary.AppendVarint(b, int64(i))
+	}
+	return b
+}
+
+// skipMessageIndexes returns the payload following the message index list.
+func skipMessageIndexes(data []byte) ([]byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 {
+		return nil, fmt.Errorf("invalid message index list")
+	}
+	data = data[n:]
+	for i := int64(0); i < count; i++ {
+		if _, n = binary.Varint(data); n <= 0 {
+			return nil, fmt.Errorf("invalid message index list")
+		}
+		data = data[n:]
+	}
+	return data, nil
+}
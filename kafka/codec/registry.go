@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SchemaType is the format of a registered schema.
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeJSON     SchemaType = "JSON"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+)
+
+// ErrSchemaNotFound is returned by a SchemaRegistry when a schema ID is unknown.
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// Schema is a schema definition stored in a SchemaRegistry.
+type Schema struct {
+	Type   SchemaType
+	Schema string
+}
+
+// SchemaRegistry stores schemas by subject and assigns each distinct schema a global ID, as the Confluent schema registry does.
+type SchemaRegistry interface {
+	// Register registers the schema under the subject and returns its ID, registering an existing schema returns the existing ID.
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	// GetByID returns the schema with the ID or an error wrapping ErrSchemaNotFound.
+	GetByID(ctx context.Context, id int) (Schema, error)
+}
+
+// MemoryRegistry is an in-memory SchemaRegistry intended for tests.
+type MemoryRegistry struct {
+	mu       sync.RWMutex
+	schemas  []Schema
+	ids      map[Schema]int
+	subjects map[string][]int
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		ids:      make(map[Schema]int),
+		subjects: make(map[string][]int),
+	}
+}
+
+func (r *MemoryRegistry) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.ids[schema]
+	if !ok {
+		r.schemas = append(r.schemas, schema)
+		id = len(r.schemas)
+		r.ids[schema] = id
+	}
+	for _, v := range r.subjects[subject] {
+		if v == id {
+			return id, nil
+		}
+	}
+	r.subjects[subject] = append(r.subjects[subject], id)
+	return id, nil
+}
+
+func (r *MemoryRegistry) GetByID(ctx context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if id < 1 || id > len(r.schemas) {
+		return Schema{}, fmt.Errorf("codec.MemoryRegistry.GetByID: schema %d: %w", id, ErrSchemaNotFound)
+	}
+	return r.schemas[id-1], nil
+}
+
+// Versions returns the schema IDs registered under the subject in registration order.
+func (r *MemoryRegistry) Versions(subject string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]int(nil), r.subjects[subject]...)
+}
@@ -0,0 +1,85 @@
+package consumer
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// partitionOffsets tracks the in-flight offsets of a partition in fetch order.
+type partitionOffsets struct {
+	pending []int64
+	done    map[int64]struct{}
+}
+
+// offsetTracker tracks in-flight messages per partition so that the stored offset only advances
+// to the highest offset below which every fetched message has been acknowledged.
+type offsetTracker map[Partition]*partitionOffsets
+
+// add records the message as in-flight.
+func (t offsetTracker) add(msg *kafka.Message) {
+	key := Partition{Topic: msg.Topic, Partition: msg.Partition}
+	p, ok := t[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]struct{})}
+		t[key] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// ack marks the message as completed and returns the highest contiguous completed offset of its partition.
+// The boolean is false when the contiguous completed offset did not advance.
+func (t offsetTracker) ack(msg *kafka.Message) (int64, bool) {
+	p, ok := t[Partition{Topic: msg.Topic, Partition: msg.Partition}]
+	if !ok {
+		return 0, false
+	}
+	p.done[msg.Offset] = struct{}{}
+	var offset int64
+	advanced := false
+	for len(p.pending) > 0 {
+		if _, ok := p.done[p.pending[0]]; !ok {
+			break
+		}
+		offset = p.pending[0]
+		delete(p.done, offset)
+		p.pending = p.pending[1:]
+		advanced = true
+	}
+	return offset, advanced
+}
+
+// Ack marks the message as processed. In CommitOnAck mode the consumed offset of the partition is advanced to the
+// highest offset up to which every fetched message has been acknowledged; in other modes it is a no-op.
+func (k *Reader) Ack(msg *kafka.Message) {
+	if k.commitMode != CommitOnAck {
+		return
+	}
+	k.commitLock.Lock()
+	defer k.commitLock.Unlock()
+	offset, ok := k.inFlight.ack(msg)
+	if !ok {
+		return
+	}
+	k.count++
+	k.consumedOffset[Partition{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+	}] = offset
+}
+
+// CommitMode returns the commit mode of the reader.
+func (k *Reader) CommitMode() CommitMode {
+	return k.commitMode
+}
+
+// TrackOffset records a message fetched outside Poll and PollBatch as in-flight, so that Ack can advance past it.
+// It is meant for CommitOnAck, Poll and PollBatch track the messages they fetch themselves.
+func (k *Reader) TrackOffset(msg *kafka.Message) {
+	k.trackOffset(msg)
+}
+
+// trackOffset records the message as in-flight until it is acknowledged.
+func (k *Reader) trackOffset(msg *kafka.Message) {
+	k.commitLock.Lock()
+	defer k.commitLock.Unlock()
+	k.inFlight.add(msg)
+}
@@ -0,0 +1,136 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sabariramc/go-kit/log/correlation"
+	"github.com/segmentio/kafka-go"
+)
+
+// Batch is a group of messages from a single topic.
+type Batch struct {
+	Topic    string
+	Messages []*MessageWithContext
+	Ctx      context.Context // Ctx carries the correlation of the batch, each message keeps its own context.
+}
+
+// KafkaMessages returns the messages of the batch.
+func (b *Batch) KafkaMessages() []*kafka.Message {
+	msgs := make([]*kafka.Message, len(b.Messages))
+	for i, msg := range b.Messages {
+		msgs[i] = msg.Message
+	}
+	return msgs
+}
+
+// pendingBatch is a batch that is still collecting messages.
+type pendingBatch struct {
+	*Batch
+	deadline time.Time
+}
+
+// PollBatch fetches messages and pushes them to ch grouped by topic. A batch is emitted when it reaches size messages
+// or when linger has elapsed since its first message, whichever happens first.
+//
+// The reader must be in CommitOnAck mode, offsets of a batch are stored only after every message in it is acknowledged through Ack or AckBatch.
+// Messages of batches that are not emitted when polling ends are not acknowledged and are delivered again.
+func (k *Reader) PollBatch(ctx context.Context, ch chan<- *Batch, size int, linger time.Duration) (offset OffsetMap, err error) {
+	if k.commitMode != CommitOnAck {
+		return nil, fmt.Errorf("kafka.Reader.PollBatch: commit mode must be %v", CommitOnAck)
+	}
+	if size < 1 || linger <= 0 {
+		return nil, fmt.Errorf("kafka.Reader.PollBatch: size and linger must be positive")
+	}
+	k.pollLock.Lock()
+	defer k.pollLock.Unlock()
+	if k.closePollCh {
+		defer close(ch)
+	}
+	msgCh := make(chan *MessageWithContext, size)
+	pollDone := make(chan struct{})
+	go func() {
+		defer close(pollDone)
+		defer close(msgCh)
+		offset, err = k.poll(ctx, msgCh)
+	}()
+	batches := make(map[string]*pendingBatch)
+	timer := time.NewTimer(linger)
+	defer timer.Stop()
+	emit := func(b *pendingBatch) bool {
+		delete(batches, b.Topic)
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- b.Batch:
+			return true
+		}
+	}
+	resetTimer := func() {
+		var next time.Time
+		for _, b := range batches {
+			if next.IsZero() || b.deadline.Before(next) {
+				next = b.deadline
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+	}
+forLoop:
+	for {
+		select {
+		case msg, ok := <-msgCh:
+			if !ok {
+				break forLoop
+			}
+			b, ok := batches[msg.Topic]
+			if !ok {
+				b = &pendingBatch{
+					Batch:    &Batch{Topic: msg.Topic, Messages: make([]*MessageWithContext, 0, size), Ctx: newBatchContext()},
+					deadline: time.Now().Add(linger),
+				}
+				batches[msg.Topic] = b
+			}
+			b.Messages = append(b.Messages, msg)
+			if len(b.Messages) >= size && !emit(b) {
+				break forLoop
+			}
+			resetTimer()
+		case <-timer.C:
+			now := time.Now()
+			for _, b := range batches {
+				if !b.deadline.After(now) && !emit(b) {
+					break forLoop
+				}
+			}
+			resetTimer()
+		}
+	}
+	for range msgCh {
+	}
+	<-pollDone
+	return
+}
+
+// AckBatch acknowledges every message in the batch.
+func (k *Reader) AckBatch(b *Batch) {
+	for _, msg := range b.Messages {
+		k.Ack(msg.Message)
+	}
+}
+
+// newBatchContext returns the context for a new batch with its own correlation.
+func newBatchContext() context.Context {
+	return correlation.GetContextWithCorrelationParam(context.Background(), &correlation.EventCorrelation{
+		CorrelationID: uuid.NewString(),
+	})
+}
@@ -23,17 +23,37 @@ const (
 
 type Config struct {
 	*kafka.ReaderConfig
-	AutoCommit  AutoCommit
-	Log         *log.Logger
-	Hooks       []Hook
-	SpanOp      span.SpanOp
-	ClosePollCh bool
+	AutoCommit    AutoCommit
+	Log           *log.Logger
+	Hooks         []Hook
+	SpanOp        span.SpanOp
+	ClosePollCh   bool
+	CommitMode    CommitMode
+	Meter         span.Meter    // Meter, when set, records lag, throughput, commit and rebalance metrics.
+	StatsInterval time.Duration // StatsInterval is the interval at which the reader statistics are recorded to the meter.
 }
 
+// CommitMode defines when the offset of a consumed message becomes eligible for commit.
+type CommitMode string
+
+const (
+	CommitOnReceive CommitMode = "on-receive" // CommitOnReceive stores the offset as soon as the message is pushed to the poll channel.
+	CommitOnAck     CommitMode = "on-ack"     // CommitOnAck stores the offset once the message and every message before it in the partition is acknowledged through Reader.Ack.
+	CommitManual    CommitMode = "manual"     // CommitManual stores offsets only through Reader.StoreOffset and commits only through Reader.Commit.
+)
+
 func ValidateConfig(config *Config) error {
 	if config.ReaderConfig == nil {
 		return fmt.Errorf("ReaderConfig is required")
 	}
+	switch config.CommitMode {
+	case CommitOnReceive, CommitOnAck, CommitManual:
+	default:
+		return fmt.Errorf("invalid CommitMode: %v", config.CommitMode)
+	}
+	if config.Meter != nil && config.StatsInterval <= 0 {
+		return fmt.Errorf("StatsInterval must be positive when Meter is set")
+	}
 	if config.AutoCommit.Enabled && config.AutoCommit.IntervalInMs == 0 {
 		return fmt.Errorf("AutoCommit.IntervalInMs must be set when AutoCommit is enabled")
 	}
@@ -78,9 +98,11 @@ func NewConfig() *Config {
 			IntervalInMs: uint64(env.GetInt(ck.EnvConsumerAutoCommitIntervalInMs, 1000)),
 			BatchSize:    uint64(env.GetInt(ck.EnvConsumerAutoCommitBatchSize, 50)),
 		},
-		Log:         logger,
-		Hooks:       []Hook{HookFunc(CorelationHook)},
-		ClosePollCh: true,
+		Log:           logger,
+		Hooks:         []Hook{HookFunc(CorelationHook)},
+		ClosePollCh:   true,
+		CommitMode:    CommitMode(env.Get(ck.EnvConsumerCommitMode, string(CommitOnReceive))),
+		StatsInterval: 10 * time.Second,
 	}
 	return config
 }
@@ -153,6 +175,22 @@ func WithGroupID(groupID string) Options {
 	}
 }
 
+// WithCommitMode sets when the offset of a consumed message becomes eligible for commit.
+func WithCommitMode(mode CommitMode) Options {
+	return func(c *Config) error {
+		c.CommitMode = mode
+		return nil
+	}
+}
+
+// WithMeter records consumer metrics to the meter, reader statistics such as rebalances are recorded every StatsInterval.
+func WithMeter(meter span.Meter) Options {
+	return func(c *Config) error {
+		c.Meter = meter
+		return nil
+	}
+}
+
 func WithoutInternalLogger() Options {
 	return func(c *Config) error {
 		if c.ReaderConfig != nil {
@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+
+	ck "github.com/sabariramc/go-kit/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+// DedupStore records the idempotency keys of processed messages.
+type DedupStore interface {
+	Exists(ctx context.Context, key string) (bool, error)
+	Add(ctx context.Context, key string) error
+}
+
+// MemoryDedupStore is an in-memory DedupStore that remembers the most recent keys up to a fixed size.
+type MemoryDedupStore struct {
+	lock  sync.Mutex
+	keys  map[string]struct{}
+	order []string
+	next  int
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore that remembers up to size keys.
+func NewMemoryDedupStore(size int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		keys:  make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+func (s *MemoryDedupStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+func (s *MemoryDedupStore) Add(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.keys[key]; ok || len(s.order) == 0 {
+		return nil
+	}
+	if evict := s.order[s.next]; evict != "" {
+		delete(s.keys, evict)
+	}
+	s.order[s.next] = key
+	s.keys[key] = struct{}{}
+	s.next = (s.next + 1) % len(s.order)
+	return nil
+}
+
+// IdempotencyKey returns the value of the ck.HeaderIdempotencyKey header of the message.
+func IdempotencyKey(msg *kafka.Message) (string, bool) {
+	for _, h := range msg.Headers {
+		if h.Key == ck.HeaderIdempotencyKey {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,153 @@
+package consumer
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/segmentio/kafka-go"
+)
+
+// Metric names recorded by the Reader.
+const (
+	MetricMessages       = "kafka.consumer.messages"
+	MetricLag            = "kafka.consumer.lag"
+	MetricCommits        = "kafka.consumer.commits"
+	MetricCommitFailures = "kafka.consumer.commit.failures"
+	MetricFetches        = "kafka.consumer.fetches"
+	MetricErrors         = "kafka.consumer.errors"
+	MetricRebalances     = "kafka.consumer.rebalances"
+)
+
+// readerMetrics holds the instruments of a Reader.
+type readerMetrics struct {
+	messages       span.Counter
+	lag            span.Gauge
+	commits        span.Counter
+	commitFailures span.Counter
+	fetches        span.Counter
+	errors         span.Counter
+	rebalances     span.Counter
+}
+
+func newReaderMetrics(meter span.Meter) *readerMetrics {
+	if meter == nil {
+		meter = span.NoopMeter{}
+	}
+	return &readerMetrics{
+		messages:       meter.Counter(MetricMessages, span.UnitCount, "Number of messages fetched"),
+		lag:            meter.Gauge(MetricLag, span.UnitCount, "Number of messages in the partition after the last fetched message"),
+		commits:        meter.Counter(MetricCommits, span.UnitCount, "Number of successful offset commits"),
+		commitFailures: meter.Counter(MetricCommitFailures, span.UnitCount, "Number of failed offset commits"),
+		fetches:        meter.Counter(MetricFetches, span.UnitCount, "Number of fetch requests sent to the brokers"),
+		errors:         meter.Counter(MetricErrors, span.UnitCount, "Number of errors reported by the reader"),
+		rebalances:     meter.Counter(MetricRebalances, span.UnitCount, "Number of consumer group rebalances"),
+	}
+}
+
+// recordFetch records throughput and lag for the fetched message and starts the commit age clock.
+func (k *Reader) recordFetch(ctx context.Context, msg *kafka.Message) {
+	lag := msg.HighWaterMark - msg.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	attrs := []span.Attribute{
+		span.Attr(span.MessagingConsumerGroupName, k.group),
+		span.Attr(span.MessagingDestinationName, msg.Topic),
+		span.Attr(span.MessagingDestinationPartitionID, strconv.Itoa(msg.Partition)),
+	}
+	k.metrics.messages.Add(ctx, 1, attrs...)
+	k.metrics.lag.Record(ctx, float64(lag), attrs...)
+	k.commitLock.Lock()
+	defer k.commitLock.Unlock()
+	k.lastFetched = Partition{Topic: msg.Topic, Partition: msg.Partition}
+	k.lag[k.lastFetched] = lag
+	if k.receivedSince.IsZero() {
+		k.receivedSince = time.Now()
+	}
+}
+
+// recordCommit records the commit result, a successful commit restarts the commit age clock. It must be called with commitLock held.
+func (k *Reader) recordCommit(ctx context.Context, err error) {
+	attr := span.Attr(span.MessagingConsumerGroupName, k.group)
+	if err != nil {
+		k.metrics.commitFailures.Add(ctx, 1, attr)
+		return
+	}
+	k.metrics.commits.Add(ctx, 1, attr)
+	k.lastCommit = time.Now()
+	k.receivedSince = time.Time{}
+	for _, p := range k.inFlight {
+		if len(p.pending) > 0 {
+			k.receivedSince = k.lastCommit
+			break
+		}
+	}
+}
+
+// collectStats periodically records the reader statistics until the context is done, the caller adds it to the wait group.
+func (k *Reader) collectStats(ctx context.Context) {
+	defer k.wg.Done()
+	ticker := time.NewTicker(k.statsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.recordStats(ctx)
+		}
+	}
+}
+
+// recordStats records the counters accumulated since the previous call to Stats and refreshes the lag.
+func (k *Reader) recordStats(ctx context.Context) {
+	stats := k.Stats()
+	attr := span.Attr(span.MessagingConsumerGroupName, k.group)
+	k.metrics.fetches.Add(ctx, stats.Fetches, attr)
+	k.metrics.errors.Add(ctx, stats.Errors, attr)
+	k.metrics.rebalances.Add(ctx, stats.Rebalances, attr)
+	k.refreshLag(ctx, stats)
+}
+
+// refreshLag raises the lag to the one in the reader statistics, which the fetcher of the reader keeps updating while
+// the application fetches no message. The statistics of a consumer group reader do not name the partition, their lag
+// is applied to the partition last fetched.
+func (k *Reader) refreshLag(ctx context.Context, stats kafka.ReaderStats) {
+	k.commitLock.Lock()
+	partition := k.lastFetched
+	if stats.Topic != "" {
+		partition.Topic = stats.Topic
+		partition.Partition, _ = strconv.Atoi(stats.Partition)
+	}
+	if partition.Topic == "" || stats.Lag <= k.lag[partition] {
+		k.commitLock.Unlock()
+		return
+	}
+	k.lag[partition] = stats.Lag
+	k.commitLock.Unlock()
+	k.metrics.lag.Record(ctx, float64(stats.Lag),
+		span.Attr(span.MessagingConsumerGroupName, k.group),
+		span.Attr(span.MessagingDestinationName, partition.Topic),
+		span.Attr(span.MessagingDestinationPartitionID, strconv.Itoa(partition.Partition)),
+	)
+}
+
+// Lag returns the number of messages after the last fetched message of each partition, as of that fetch or the last
+// refresh from the reader statistics.
+func (k *Reader) Lag() OffsetMap {
+	k.commitLock.Lock()
+	defer k.commitLock.Unlock()
+	return k.lag.Copy()
+}
+
+// CommitAge returns the time since the oldest message received after the last commit, or zero when every received message is committed.
+func (k *Reader) CommitAge() time.Duration {
+	k.commitLock.Lock()
+	defer k.commitLock.Unlock()
+	if k.receivedSince.IsZero() {
+		return 0
+	}
+	return time.Since(k.receivedSince)
+}
@@ -76,6 +76,16 @@ type Reader struct {
 	hooks            []Hook
 	tr               span.SpanOp
 	closePollCh      bool
+	commitMode       CommitMode
+	inFlight         offsetTracker
+	metrics          *readerMetrics
+	group            string
+	collectMetrics   bool
+	statsInterval    time.Duration
+	lag              OffsetMap
+	lastFetched      Partition
+	lastCommit       time.Time
+	receivedSince    time.Time
 }
 
 func New(ctx context.Context, options ...Options) (*Reader, error) {
@@ -90,6 +100,10 @@ func New(ctx context.Context, options ...Options) (*Reader, error) {
 	if err != nil {
 		return nil, fmt.Errorf("kafka.Reader.New: config validation error: %w", err)
 	}
+	if config.CommitMode == CommitManual && config.AutoCommit.Enabled {
+		config.Log.Info(ctx).Msg("auto commit is disabled in manual commit mode")
+		config.AutoCommit.Enabled = false
+	}
 	reader := kafka.NewReader(*config.ReaderConfig)
 	k := &Reader{
 		Reader:         reader,
@@ -100,6 +114,13 @@ func New(ctx context.Context, options ...Options) (*Reader, error) {
 		tr:             config.SpanOp,
 		hooks:          config.Hooks,
 		closePollCh:    config.ClosePollCh,
+		commitMode:     config.CommitMode,
+		inFlight:       make(offsetTracker),
+		metrics:        newReaderMetrics(config.Meter),
+		group:          config.ReaderConfig.GroupID,
+		collectMetrics: config.Meter != nil,
+		statsInterval:  config.StatsInterval,
+		lag:            make(OffsetMap),
 	}
 	return k, nil
 }
@@ -121,6 +142,11 @@ func (k *Reader) Poll(ctx context.Context, ch chan<- *MessageWithContext) (offse
 	if k.closePollCh {
 		defer close(ch)
 	}
+	return k.poll(ctx, ch)
+}
+
+// poll fetches messages and pushes them to ch until the context is cancelled or the reader is closed.
+func (k *Reader) poll(ctx context.Context, ch chan<- *MessageWithContext) (offset OffsetMap, err error) {
 	k.wg.Add(1)
 	defer k.wg.Done()
 	k.log.Info(ctx).Array("topics", k.topics).Msg("Polling started for topics")
@@ -128,10 +154,14 @@ func (k *Reader) Poll(ctx context.Context, ch chan<- *MessageWithContext) (offse
 	readerClosed, cancel := context.WithCancel(ctx)
 	k.pollCancel = cancel
 	go k.autoCommitTimeBased(readerClosed)
-	var msg kafka.Message
+	if k.collectMetrics {
+		k.wg.Add(1)
+		go k.collectStats(readerClosed)
+	}
 	var commitErr error
 forLoop:
 	for {
+		var msg kafka.Message
 		msg, err = k.FetchMessage(ctx)
 		if err != nil {
 			err = fmt.Errorf("error fetching message: %w", err)
@@ -140,15 +170,21 @@ forLoop:
 			}
 			break
 		}
+		k.recordFetch(ctx, &msg)
+		if k.commitMode == CommitOnAck {
+			k.trackOffset(&msg)
+		}
 		select {
 		case <-ctx.Done():
-			offset, commitErr = k.Commit(nCtx)
+			offset, commitErr = k.commitOnShutdown(nCtx)
 			err = ctx.Err()
 			break forLoop
 		case <-readerClosed.Done():
 			break forLoop
 		case ch <- &MessageWithContext{Message: &msg, Ctx: k.getMessageContext(&msg)}:
-			k.storeOffset(&msg)
+			if k.commitMode == CommitOnReceive {
+				k.storeOffset(&msg)
+			}
 			offset, commitErr = k.autoCommitSizeBased(nCtx)
 			if commitErr != nil {
 				break forLoop
@@ -201,14 +237,14 @@ func (k *Reader) autoCommitTimeBased(ctx context.Context) {
 			timeout, _ = context.WithTimeout(context.Background(), time.Duration(k.autoCommit.IntervalInMs)*time.Millisecond)
 		case <-ctx.Done():
 			k.log.Debug(ctx).Msg("context cancelled, committing messages")
-			_, err := k.Commit(nCtx)
+			_, err := k.commitOnShutdown(nCtx)
 			if err != nil {
 				k.log.Error(nCtx).Err(err).Msg("error in auto commit")
 			}
 			return
 		case <-readerClosed.Done():
 			k.log.Debug(ctx).Msg("reader closed, committing messages")
-			_, err := k.Commit(nCtx)
+			_, err := k.commitOnShutdown(nCtx)
 			if err != nil {
 				k.log.Error(nCtx).Err(err).Msg("error in auto commit")
 			}
@@ -216,6 +252,15 @@ func (k *Reader) autoCommitTimeBased(ctx context.Context) {
 	}
 }
 
+// commitOnShutdown commits the stored offsets when polling stops, except in CommitManual mode where offsets are
+// committed by the application only.
+func (k *Reader) commitOnShutdown(ctx context.Context) (OffsetMap, error) {
+	if k.commitMode == CommitManual {
+		return nil, nil
+	}
+	return k.Commit(ctx)
+}
+
 func (k *Reader) Close(ctx context.Context) error {
 	k.log.Info(ctx).Array("topics", k.topics).Msg("Consumer closer initiated")
 	if k.pollCancel != nil {
@@ -224,6 +269,11 @@ func (k *Reader) Close(ctx context.Context) error {
 	if k.autoCommitCancel != nil {
 		k.autoCommitCancel()
 	}
+	if k.commitMode == CommitOnAck && k.autoCommit.Enabled {
+		if _, err := k.Commit(context.WithoutCancel(ctx)); err != nil {
+			k.log.Error(ctx).Array("topics", k.topics).Err(err).Msg("error committing acknowledged messages")
+		}
+	}
 	closeErr := k.Reader.Close()
 	if closeErr != nil {
 		k.log.Error(ctx).Array("topics", k.topics).Err(closeErr).Msg("Consumer closed with error")
@@ -256,6 +306,7 @@ func (k *Reader) Commit(ctx context.Context) (OffsetMap, error) {
 	}
 	k.log.Debug(ctx).Object("offsets", k.consumedOffset).Uint64("no_of_messages", k.count).Msg("initiating commit")
 	err := k.CommitMessages(ctx, msgList...)
+	k.recordCommit(ctx, err)
 	if err != nil {
 		return nil, fmt.Errorf("kafka.Reader.Commit: error committing message: %w", err)
 	}
@@ -271,6 +322,12 @@ func (k *Reader) GetOffsets() (committedOffset OffsetMap, consumedOffset OffsetM
 	return k.committedOffset.Copy(), k.consumedOffset.Copy()
 }
 
+// StoreOffset marks the message as consumed so that its offset is included in the next commit.
+// It is meant for CommitManual, in other modes the reader stores offsets itself.
+func (k *Reader) StoreOffset(msg *kafka.Message) {
+	k.storeOffset(msg)
+}
+
 func (k *Reader) storeOffset(msg *kafka.Message) {
 	k.commitLock.Lock()
 	defer k.commitLock.Unlock()
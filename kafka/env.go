@@ -13,4 +13,5 @@ const (
 	EnvConsumerAutoCommit             = "KAFKA__CONSUMER__AUTO_COMMIT"
 	EnvConsumerAutoCommitIntervalInMs = "KAFKA__CONSUMER__AUTO_COMMIT_INTERVAL_IN_MS"
 	EnvConsumerAutoCommitBatchSize    = "KAFKA__CONSUMER__AUTO_COMMIT_BATCH_SIZE"
+	EnvConsumerCommitMode             = "KAFKA__CONSUMER__COMMIT_MODE"
 )
@@ -0,0 +1,7 @@
+package kafka
+
+const (
+	// HeaderIdempotencyKey carries a key that is stable across retries of the same logical message,
+	// consumers use it to drop duplicate deliveries.
+	HeaderIdempotencyKey = "X-Idempotency-Key"
+)
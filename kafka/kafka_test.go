@@ -2,18 +2,27 @@ package kafka_test
 
 import (
 	"context"
+	"fmt"
 	"math/rand/v2"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/instrumentation/memory"
+	"github.com/sabariramc/go-kit/kafka/codec"
 	"github.com/sabariramc/go-kit/kafka/consumer"
+	"github.com/sabariramc/go-kit/kafka/outbox"
+	"github.com/sabariramc/go-kit/kafka/pipeline"
 	"github.com/sabariramc/go-kit/kafka/producer"
 	"github.com/sabariramc/go-kit/log"
 	"github.com/sabariramc/go-kit/log/correlation"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 	"gotest.tools/v3/assert"
 )
 
@@ -23,14 +32,17 @@ func TestKafkaPollSyncWriter(t *testing.T) {
 		CorrelationID: "TestKafkaPoll" + uuid.NewString(),
 		ScenarioID:    "TestKafkaPoll",
 	})
+	meter := memory.NewMeter()
 	pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
 		c.Writer.Async = false
 		c.Writer.AllowAutoTopicCreation = true
 		return nil
-	}, producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+	}, producer.WithMeter(meter), producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
 	assert.NilError(t, err)
 	defer pr.Close(ctx)
 	testKafkaPoll(t, ctx, "TestKafkaPoll", pr, 10)
+	assert.Equal(t, meter.Sum(producer.MetricMessages, span.Attr(span.MessagingDestinationName, "TestKafkaPoll")), float64(10))
+	assert.Equal(t, meter.Count(producer.MetricWriteDuration), 10)
 }
 
 func TestKafkaPollAsyncWriter(t *testing.T) {
@@ -248,3 +260,480 @@ func testKafkaPoll(t *testing.T, ctx context.Context, topic string, pr *producer
 	logger.Info(ctx).Msgf("Time taken in ms: %d", time.Since(st)/1000000)
 	assert.Equal(t, totalCount, count)
 }
+
+func TestKafkaPollAckOffsets(t *testing.T) {
+	t.Parallel()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: "TestKafkaPollAckOffsets" + uuid.NewString(),
+		ScenarioID:    "TestKafkaPollAckOffsets",
+	})
+	topic := "TestKafkaPollAckOffsets"
+	pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
+		c.Writer.Async = false
+		c.Writer.AllowAutoTopicCreation = true
+		return nil
+	}, producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer pr.Close(ctx)
+	uuidVal := "TestKafkaPollAckOffsets" + uuid.NewString()
+	for i := 0; i < 5; i++ {
+		err = pr.WriteMessages(ctx, kafka.Message{
+			Topic: topic,
+			Key:   []byte(uuidVal),
+			Value: []byte(strconv.Itoa(i)),
+		})
+		assert.NilError(t, err)
+	}
+	co, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{topic}
+		c.ReaderConfig.GroupID = topic
+		c.AutoCommit.Enabled = false
+		return nil
+	}, consumer.WithCommitMode(consumer.CommitOnAck), consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer co.Close(ctx)
+	tCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan *consumer.MessageWithContext, 100)
+	go co.Poll(tCtx, ch)
+	msgs := make([]*kafka.Message, 0, 5)
+	for i := range ch {
+		if string(i.Key) != uuidVal {
+			co.Ack(i.Message)
+			continue
+		}
+		msgs = append(msgs, i.Message)
+		if len(msgs) == 5 {
+			break
+		}
+	}
+	partition := consumer.Partition{Topic: topic, Partition: msgs[0].Partition}
+	consumedOffset := func() (int64, bool) {
+		_, consumed := co.GetOffsets()
+		offset, ok := consumed[partition]
+		return offset, ok
+	}
+	co.Ack(msgs[1])
+	co.Ack(msgs[2])
+	_, ok := consumedOffset()
+	assert.Assert(t, !ok)
+	co.Ack(msgs[0])
+	offset, _ := consumedOffset()
+	assert.Equal(t, offset, msgs[2].Offset)
+	co.Ack(msgs[4])
+	offset, _ = consumedOffset()
+	assert.Equal(t, offset, msgs[2].Offset)
+	co.Ack(msgs[3])
+	offset, _ = consumedOffset()
+	assert.Equal(t, offset, msgs[4].Offset)
+}
+
+func TestKafkaPollBatch(t *testing.T) {
+	t.Parallel()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: "TestKafkaPollBatch" + uuid.NewString(),
+		ScenarioID:    "TestKafkaPollBatch",
+	})
+	topic := "TestKafkaPollBatch"
+	pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
+		c.Writer.Async = false
+		c.Writer.AllowAutoTopicCreation = true
+		return nil
+	}, producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer pr.Close(ctx)
+	totalCount := 25
+	uuidVal := "TestKafkaPollBatch" + uuid.NewString()
+	for i := 0; i < totalCount; i++ {
+		err = pr.WriteMessages(ctx, kafka.Message{
+			Topic: topic,
+			Key:   []byte(uuidVal),
+			Value: []byte(strconv.Itoa(i)),
+		})
+		assert.NilError(t, err)
+	}
+	co, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{topic}
+		c.ReaderConfig.GroupID = topic
+		return nil
+	}, consumer.WithCommitMode(consumer.CommitOnAck), consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer co.Close(ctx)
+	tCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan *consumer.Batch, 10)
+	go co.PollBatch(tCtx, ch, 10, 500*time.Millisecond)
+	count := 0
+	for batch := range ch {
+		assert.Assert(t, len(batch.Messages) <= 10)
+		for _, msg := range batch.Messages {
+			assert.Equal(t, msg.Topic, batch.Topic)
+			if string(msg.Key) == uuidVal {
+				count++
+			}
+		}
+		co.AckBatch(batch)
+		if count == totalCount {
+			cancel()
+		}
+	}
+	assert.Equal(t, count, totalCount)
+}
+
+func TestKafkaPollManualCommit(t *testing.T) {
+	t.Parallel()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: "TestKafkaPollManualCommit" + uuid.NewString(),
+		ScenarioID:    "TestKafkaPollManualCommit",
+	})
+	topic := "TestKafkaPollManualCommit"
+	pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
+		c.Writer.Async = false
+		c.Writer.AllowAutoTopicCreation = true
+		return nil
+	}, producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer pr.Close(ctx)
+	err = pr.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(uuid.NewString()),
+		Value: []byte("manual"),
+	})
+	assert.NilError(t, err)
+	co, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{topic}
+		c.ReaderConfig.GroupID = topic
+		return nil
+	}, consumer.WithCommitMode(consumer.CommitManual), consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer co.Close(ctx)
+	tCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan *consumer.MessageWithContext, 10)
+	go co.Poll(tCtx, ch)
+	msg := <-ch
+	co.Ack(msg.Message)
+	_, consumed := co.GetOffsets()
+	assert.Equal(t, len(consumed), 0)
+	co.StoreOffset(msg.Message)
+	_, consumed = co.GetOffsets()
+	assert.Equal(t, consumed[consumer.Partition{Topic: topic, Partition: msg.Partition}], msg.Offset)
+	committed, err := co.Commit(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, committed[consumer.Partition{Topic: topic, Partition: msg.Partition}], msg.Offset)
+}
+
+func TestCommitModeValidation(t *testing.T) {
+	_, err := consumer.New(context.TODO(), consumer.WithCommitMode("on-complete"))
+	assert.ErrorContains(t, err, "invalid CommitMode")
+}
+
+func TestIdempotencyHook(t *testing.T) {
+	ctx := context.Background()
+	msg := &kafka.Message{Topic: "topic", Key: []byte("key"), Value: []byte("value")}
+	hook := producer.IdempotencyHook{}
+	assert.NilError(t, hook.Run(ctx, msg))
+	key, ok := consumer.IdempotencyKey(msg)
+	assert.Assert(t, ok)
+	assert.NilError(t, hook.Run(ctx, msg))
+	assert.Equal(t, len(msg.Headers), 1)
+	other := &kafka.Message{Topic: "topic", Key: []byte("key"), Value: []byte("value")}
+	assert.NilError(t, hook.Run(ctx, other))
+	otherKey, _ := consumer.IdempotencyKey(other)
+	assert.Assert(t, otherKey != key)
+	content := &kafka.Message{Topic: "topic", Key: []byte("key"), Value: []byte("value")}
+	assert.NilError(t, producer.IdempotencyHook{KeyFunc: producer.ContentIdempotencyKey}.Run(ctx, content))
+	contentKey, _ := consumer.IdempotencyKey(content)
+	assert.Equal(t, contentKey, producer.ContentIdempotencyKey(ctx, other))
+	store := consumer.NewMemoryDedupStore(2)
+	for _, k := range []string{"a", "b", "c"} {
+		assert.NilError(t, store.Add(ctx, k))
+	}
+	for k, exists := range map[string]bool{"a": false, "b": true, "c": true} {
+		ok, err := store.Exists(ctx, k)
+		assert.NilError(t, err)
+		assert.Equal(t, ok, exists, k)
+	}
+}
+
+func TestKafkaPipeline(t *testing.T) {
+	t.Parallel()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: "TestKafkaPipeline" + uuid.NewString(),
+		ScenarioID:    "TestKafkaPipeline",
+	})
+	source, sink := "TestKafkaPipeline.Source", "TestKafkaPipeline.Sink"
+	newWriter := func() *producer.Writer {
+		pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
+			c.Writer.Async = false
+			c.Writer.AllowAutoTopicCreation = true
+			return nil
+		}, producer.WithIdempotency(nil), producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+		assert.NilError(t, err)
+		return pr
+	}
+	pr := newWriter()
+	defer pr.Close(ctx)
+	totalCount := 20
+	uuidVal := "TestKafkaPipeline" + uuid.NewString()
+	for i := 0; i < totalCount; i++ {
+		err := pr.WriteMessages(ctx, kafka.Message{Topic: source, Key: []byte(uuidVal), Value: []byte(strconv.Itoa(i))})
+		assert.NilError(t, err)
+	}
+	co, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{source}
+		c.ReaderConfig.GroupID = source
+		return nil
+	}, consumer.WithCommitMode(consumer.CommitOnAck), consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer co.Close(ctx)
+	sinkWriter := newWriter()
+	defer sinkWriter.Close(ctx)
+	pl, err := pipeline.New(pipeline.WithReader(co), pipeline.WithWriter(sinkWriter), pipeline.WithTransform(func(ctx context.Context, msg *kafka.Message) ([]kafka.Message, error) {
+		if string(msg.Key) != uuidVal {
+			return nil, nil
+		}
+		return []kafka.Message{{Topic: sink, Key: msg.Key, Value: append([]byte("transformed-"), msg.Value...)}}, nil
+	}))
+	assert.NilError(t, err)
+	tCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go pl.Run(tCtx)
+	sinkReader, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{sink}
+		c.ReaderConfig.GroupID = sink
+		return nil
+	}, consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer sinkReader.Close(ctx)
+	ch := make(chan *consumer.MessageWithContext, 100)
+	go sinkReader.Poll(tCtx, ch)
+	count := 0
+	for msg := range ch {
+		if string(msg.Key) != uuidVal {
+			continue
+		}
+		key, ok := consumer.IdempotencyKey(msg.Message)
+		assert.Assert(t, ok)
+		assert.Assert(t, strings.HasPrefix(key, source+":"))
+		assert.Assert(t, strings.HasPrefix(string(msg.Value), "transformed-"))
+		count++
+		if count == totalCount {
+			cancel()
+		}
+	}
+	assert.Equal(t, count, totalCount)
+}
+
+type outboxWriter struct {
+	mu       sync.Mutex
+	failures int
+	msgs     []kafka.Message
+}
+
+func (w *outboxWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return fmt.Errorf("broker unavailable")
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *outboxWriter) messages() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.msgs...)
+}
+
+func TestOutbox(t *testing.T) {
+	correlationID := "TestOutbox" + uuid.NewString()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: correlationID,
+		ScenarioID:    "TestOutbox",
+	})
+	store := outbox.NewMemoryStore()
+	writer := &outboxWriter{failures: 2}
+	ob, err := outbox.New(outbox.WithStore(store), outbox.WithWriter(writer), outbox.WithBatchSize(3),
+		outbox.WithPollInterval(10*time.Millisecond), outbox.WithRetryWait(10*time.Millisecond, 20*time.Millisecond))
+	assert.NilError(t, err)
+	totalCount := 10
+	for i := 0; i < totalCount; i++ {
+		err := ob.Enqueue(ctx, nil, kafka.Message{Topic: "TestOutbox", Value: []byte(strconv.Itoa(i))})
+		assert.NilError(t, err)
+	}
+	ob.Start()
+	for i := 0; i < 100 && store.Len() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NilError(t, ob.Close(ctx))
+	assert.Equal(t, store.Len(), 0)
+	msgs := writer.messages()
+	assert.Equal(t, len(msgs), totalCount)
+	for i, msg := range msgs {
+		assert.Equal(t, string(msg.Value), strconv.Itoa(i))
+		found := false
+		for _, h := range msg.Headers {
+			if h.Key == correlation.CorrelationIDHeader {
+				assert.Equal(t, string(h.Value), correlationID)
+				found = true
+			}
+		}
+		assert.Assert(t, found, "correlation header is not captured at enqueue time")
+	}
+}
+
+func TestOutboxSQLStore(t *testing.T) {
+	ctx := context.Background()
+	cases := map[string]struct {
+		placeholder    outbox.Placeholder
+		table          string
+		insert, update string
+	}{
+		"question": {
+			placeholder: outbox.QuestionPlaceholder,
+			insert:      "INSERT INTO kafka_outbox (topic, msg_key, msg_value, headers, created_at) VALUES (?, ?, ?, ?, ?)",
+			update:      "UPDATE kafka_outbox SET sent_at = ? WHERE id = ?",
+		},
+		"dollar": {
+			placeholder: outbox.DollarPlaceholder,
+			table:       "events_outbox",
+			insert:      "INSERT INTO events_outbox (topic, msg_key, msg_value, headers, created_at) VALUES ($1, $2, $3, $4, $5)",
+			update:      "UPDATE events_outbox SET sent_at = $1 WHERE id = $2",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NilError(t, err)
+			defer db.Close()
+			table := tc.table
+			if table == "" {
+				table = outbox.DefaultTable
+			}
+			store := outbox.NewSQLStore(db, tc.table, tc.placeholder)
+			msg := kafka.Message{Topic: "TestOutboxSQLStore", Key: []byte("key"), Value: []byte("value"), Headers: []kafka.Header{{Key: "h", Value: []byte("v")}}}
+			headers := `[{"Key":"h","Value":"dg=="}]`
+			mock.ExpectBegin()
+			mock.ExpectExec(tc.insert).WithArgs(msg.Topic, msg.Key, msg.Value, headers, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			tx, err := db.Begin()
+			assert.NilError(t, err)
+			assert.NilError(t, store.Add(ctx, tx, msg))
+			assert.NilError(t, tx.Commit())
+			mock.ExpectExec(tc.insert).WillReturnError(fmt.Errorf("insert failed"))
+			assert.ErrorContains(t, store.Add(ctx, nil, msg), "insert failed")
+			created := time.Now().UTC().Truncate(time.Second)
+			mock.ExpectQuery("SELECT id, topic, msg_key, msg_value, headers, created_at FROM " + table + " WHERE sent_at IS NULL ORDER BY id LIMIT 10").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "msg_key", "msg_value", "headers", "created_at"}).
+					AddRow(int64(1), msg.Topic, msg.Key, msg.Value, headers, created))
+			records, err := store.Pending(ctx, 10)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, records, []outbox.Record{{ID: 1, Message: msg, CreatedAt: created}})
+			mock.ExpectBegin()
+			mock.ExpectExec(tc.update).WithArgs(sqlmock.AnyArg(), int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(tc.update).WithArgs(sqlmock.AnyArg(), int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			assert.NilError(t, store.MarkSent(ctx, 1, 2))
+			mock.ExpectBegin()
+			mock.ExpectExec(tc.update).WithArgs(sqlmock.AnyArg(), int64(3)).WillReturnError(fmt.Errorf("update failed"))
+			mock.ExpectRollback()
+			assert.ErrorContains(t, store.MarkSent(ctx, 3), "update failed")
+			assert.NilError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+type codecEvent struct {
+	ID    string `json:"id" avro:"id"`
+	Count int    `json:"count" avro:"count"`
+}
+
+func TestCodecs(t *testing.T) {
+	ctx := context.Background()
+	registry := codec.NewMemoryRegistry()
+	avroCodec, err := codec.NewAvroCodec(registry, `{"type":"record","name":"Event","fields":[{"name":"id","type":"string"},{"name":"count","type":"int"}]}`, nil)
+	assert.NilError(t, err)
+	codecs := map[string]codec.Codec{
+		"json":        codec.NewJSONCodec(nil, "", nil),
+		"json-schema": codec.NewJSONCodec(registry, `{"type":"object"}`, nil),
+		"avro":        avroCodec,
+	}
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			in := codecEvent{ID: "event-1", Count: 3}
+			data, err := c.Encode(ctx, "TestCodecs", in)
+			assert.NilError(t, err)
+			var out codecEvent
+			assert.NilError(t, c.Decode(ctx, "TestCodecs", data, &out))
+			assert.DeepEqual(t, in, out)
+		})
+	}
+	data, err := avroCodec.Encode(ctx, "TestCodecs", codecEvent{ID: "event-1"})
+	assert.NilError(t, err)
+	id, _, err := codec.ParseWireHeader(data)
+	assert.NilError(t, err)
+	schema, err := registry.GetByID(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, schema.Type, codec.SchemaTypeAvro)
+	assert.Equal(t, len(registry.Versions("TestCodecs-value")), 2)
+	var out codecEvent
+	err = codecs["json-schema"].Decode(ctx, "TestCodecs", data, &out)
+	assert.ErrorContains(t, err, "expected JSON")
+
+	protoCodec := codec.NewProtobufCodec(registry, `syntax = "proto3"; message StringValue { string value = 1; }`, nil)
+	data, err = protoCodec.Encode(ctx, "TestCodecsProto", wrapperspb.String("value"))
+	assert.NilError(t, err)
+	var msg *wrapperspb.StringValue
+	assert.NilError(t, protoCodec.Decode(ctx, "TestCodecsProto", data, &msg))
+	assert.Equal(t, msg.GetValue(), "value")
+	_, err = protoCodec.Encode(ctx, "TestCodecsProto", codecEvent{})
+	assert.ErrorContains(t, err, "not a proto.Message")
+}
+
+func TestKafkaConsumerMetrics(t *testing.T) {
+	t.Parallel()
+	ctx := correlation.GetContextWithCorrelationParam(context.TODO(), &correlation.EventCorrelation{
+		CorrelationID: "TestKafkaConsumerMetrics" + uuid.NewString(),
+		ScenarioID:    "TestKafkaConsumerMetrics",
+	})
+	topic := "TestKafkaConsumerMetrics"
+	pr, err := producer.New(context.TODO(), func(c *producer.Config) error {
+		c.Writer.Async = false
+		c.Writer.AllowAutoTopicCreation = true
+		return nil
+	}, producer.WithLogger(log.New(producer.ModuleProducer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer pr.Close(ctx)
+	totalCount := 5
+	for i := 0; i < totalCount; i++ {
+		err = pr.WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(uuid.NewString()), Value: []byte(strconv.Itoa(i))})
+		assert.NilError(t, err)
+	}
+	meter := memory.NewMeter()
+	co, err := consumer.New(ctx, func(c *consumer.Config) error {
+		c.ReaderConfig.GroupTopics = []string{topic}
+		c.ReaderConfig.GroupID = topic + uuid.NewString()
+		c.ReaderConfig.StartOffset = kafka.FirstOffset
+		c.StatsInterval = 100 * time.Millisecond
+		return nil
+	}, consumer.WithCommitMode(consumer.CommitManual), consumer.WithMeter(meter), consumer.WithLogger(log.New(consumer.ModuleConsumer, log.WithLogger(&logger.Logger))))
+	assert.NilError(t, err)
+	defer co.Close(ctx)
+	tCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan *consumer.MessageWithContext, 10)
+	go co.Poll(tCtx, ch)
+	msg := <-ch
+	assert.Assert(t, co.CommitAge() > 0)
+	assert.Assert(t, len(co.Lag()) > 0)
+	co.StoreOffset(msg.Message)
+	_, err = co.Commit(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, meter.Sum(consumer.MetricCommits), float64(1))
+	assert.Assert(t, meter.Sum(consumer.MetricMessages) >= 1)
+	time.Sleep(200 * time.Millisecond)
+	assert.Assert(t, meter.Sum(consumer.MetricFetches) >= 1)
+}
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sabariramc/go-kit/kafka/producer"
+	"github.com/sabariramc/go-kit/log"
+)
+
+const (
+	ModuleOutbox = "KafkaOutbox"
+)
+
+type Config struct {
+	Store        OutboxStore
+	Writer       Writer
+	Hooks        []producer.Hook // Hooks run on every message at enqueue time, while the caller's context is available.
+	BatchSize    int             // BatchSize is the maximum number of records relayed in one write.
+	PollInterval time.Duration   // PollInterval is the wait between polls when the store has no pending records.
+	MinRetryWait time.Duration   // MinRetryWait is the wait after the first failed relay attempt.
+	MaxRetryWait time.Duration   // MaxRetryWait caps the exponential backoff between failed relay attempts.
+	Log          *log.Logger
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Hooks:        []producer.Hook{producer.HookFunc(producer.CorelationHook)},
+		BatchSize:    100,
+		PollInterval: time.Second,
+		MinRetryWait: 100 * time.Millisecond,
+		MaxRetryWait: 30 * time.Second,
+		Log:          log.New(ModuleOutbox),
+	}
+}
+
+func ValidateConfig(config *Config) error {
+	if config.Store == nil {
+		return fmt.Errorf("outbox store is not configured")
+	}
+	if config.Writer == nil {
+		return fmt.Errorf("kafka writer is not configured")
+	}
+	if w, ok := config.Writer.(*producer.Writer); ok && w.Async {
+		return fmt.Errorf("kafka writer must be synchronous")
+	}
+	if config.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+	if config.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive")
+	}
+	if config.MinRetryWait <= 0 || config.MinRetryWait > config.MaxRetryWait {
+		return fmt.Errorf("retry wait must be positive and MinRetryWait must not exceed MaxRetryWait")
+	}
+	if config.Log == nil {
+		return fmt.Errorf("logger is not configured")
+	}
+	return nil
+}
+
+type Options func(*Config) error
+
+func WithStore(store OutboxStore) Options {
+	return func(c *Config) error {
+		c.Store = store
+		return nil
+	}
+}
+
+func WithWriter(writer Writer) Options {
+	return func(c *Config) error {
+		c.Writer = writer
+		return nil
+	}
+}
+
+// WithHooks adds hooks that run on every message at enqueue time.
+func WithHooks(hooks ...producer.Hook) Options {
+	return func(c *Config) error {
+		c.Hooks = append(c.Hooks, hooks...)
+		return nil
+	}
+}
+
+func WithBatchSize(size int) Options {
+	return func(c *Config) error {
+		c.BatchSize = size
+		return nil
+	}
+}
+
+func WithPollInterval(interval time.Duration) Options {
+	return func(c *Config) error {
+		c.PollInterval = interval
+		return nil
+	}
+}
+
+func WithRetryWait(min, max time.Duration) Options {
+	return func(c *Config) error {
+		c.MinRetryWait = min
+		c.MaxRetryWait = max
+		return nil
+	}
+}
+
+func WithLogger(logger *log.Logger) Options {
+	return func(c *Config) error {
+		c.Log = logger
+		return nil
+	}
+}
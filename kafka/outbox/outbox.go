@@ -0,0 +1,173 @@
+// Package outbox implements the transactional outbox pattern: messages are stored in the caller's database transaction
+// and relayed to Kafka in order by a background goroutine once the transaction commits.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sabariramc/go-kit/kafka/producer"
+	"github.com/sabariramc/go-kit/log"
+	"github.com/segmentio/kafka-go"
+)
+
+// Writer publishes messages to Kafka, it is satisfied by a synchronous *producer.Writer.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Outbox stores messages in an OutboxStore and relays them to a Writer.
+//
+// Outbox implements base.ShutdownHook, Close stops the relay after the in-flight batch. A store must be relayed by a
+// single Outbox at a time, concurrent relays publish the same records.
+type Outbox struct {
+	store        OutboxStore
+	writer       Writer
+	hooks        []producer.Hook
+	batchSize    int
+	pollInterval time.Duration
+	minRetryWait time.Duration
+	maxRetryWait time.Duration
+	log          *log.Logger
+	startOnce    sync.Once
+	stopOnce     sync.Once
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+func New(opt ...Options) (*Outbox, error) {
+	cfg := NewDefaultConfig()
+	for _, o := range opt {
+		if err := o(cfg); err != nil {
+			return nil, fmt.Errorf("kafka.Outbox.New: error applying option: %w", err)
+		}
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("kafka.Outbox.New: config validation error: %w", err)
+	}
+	return &Outbox{
+		store:        cfg.Store,
+		writer:       cfg.Writer,
+		hooks:        cfg.Hooks,
+		batchSize:    cfg.BatchSize,
+		pollInterval: cfg.PollInterval,
+		minRetryWait: cfg.MinRetryWait,
+		maxRetryWait: cfg.MaxRetryWait,
+		log:          cfg.Log,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// Enqueue runs the enqueue hooks on the messages and adds them to the store within tx.
+//
+// Hooks run here rather than in the relay so that headers derived from the context, such as correlation headers, belong to the caller's request.
+func (o *Outbox) Enqueue(ctx context.Context, tx *sql.Tx, msgs ...kafka.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	for i := range msgs {
+		for _, hook := range o.hooks {
+			if err := hook.Run(ctx, &msgs[i]); err != nil {
+				return fmt.Errorf("kafka.Outbox.Enqueue: error executing hook: %w", err)
+			}
+		}
+	}
+	if err := o.store.Add(ctx, tx, msgs...); err != nil {
+		return fmt.Errorf("kafka.Outbox.Enqueue: %w", err)
+	}
+	return nil
+}
+
+// Start starts the relay goroutine, it is a no-op if the relay is already started.
+//
+// The relay runs on a background context so that the writer's hooks do not add headers from the context that started it.
+func (o *Outbox) Start() {
+	o.startOnce.Do(func() {
+		go o.relay(context.Background())
+	})
+}
+
+// Close stops the relay and waits for it to exit or for the context to be done.
+func (o *Outbox) Close(ctx context.Context) error {
+	o.stopOnce.Do(func() { close(o.stop) })
+	started := true
+	o.startOnce.Do(func() {
+		started = false
+		close(o.done)
+	})
+	if !started {
+		return nil
+	}
+	select {
+	case <-o.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("kafka.Outbox.Close: relay did not stop: %w", ctx.Err())
+	}
+}
+
+// relay drains the store into the writer until the outbox is closed.
+func (o *Outbox) relay(ctx context.Context) {
+	defer close(o.done)
+	failures := 0
+	for {
+		sent, err := o.flush(ctx)
+		wait := o.pollInterval
+		if err != nil {
+			failures++
+			wait = o.backoff(failures)
+			o.log.Error(ctx).Err(err).Int("failures", failures).Msgf("outbox relay failed, retrying in %v", wait)
+		} else {
+			failures = 0
+			if sent == o.batchSize {
+				wait = 0
+			}
+		}
+		select {
+		case <-o.stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// flush publishes one batch of pending records in order and marks them as sent, it returns the number of records sent.
+func (o *Outbox) flush(ctx context.Context) (int, error) {
+	records, err := o.store.Pending(ctx, o.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("kafka.Outbox.flush: error reading pending messages: %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	msgs := make([]kafka.Message, len(records))
+	ids := make([]int64, len(records))
+	for i, r := range records {
+		msgs[i] = r.Message
+		ids[i] = r.ID
+	}
+	if err := o.writer.WriteMessages(ctx, msgs...); err != nil {
+		return 0, fmt.Errorf("kafka.Outbox.flush: error writing messages: %w", err)
+	}
+	if err := o.store.MarkSent(ctx, ids...); err != nil {
+		return 0, fmt.Errorf("kafka.Outbox.flush: error marking messages as sent: %w", err)
+	}
+	o.log.Debug(ctx).Int("count", len(records)).Msg("outbox messages relayed")
+	return len(records), nil
+}
+
+// backoff returns the wait duration after the given number of consecutive failures.
+func (o *Outbox) backoff(failures int) time.Duration {
+	wait := o.minRetryWait
+	for i := 1; i < failures && wait < o.maxRetryWait; i++ {
+		wait *= 2
+	}
+	if wait > o.maxRetryWait {
+		wait = o.maxRetryWait
+	}
+	return wait
+}
@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultTable is the table used by SQLStore when no table name is given.
+const DefaultTable = "kafka_outbox"
+
+// Placeholder returns the bind parameter for the n-th (1 based) argument of a query.
+type Placeholder func(n int) string
+
+// QuestionPlaceholder is the placeholder style used by MySQL and SQLite.
+func QuestionPlaceholder(n int) string {
+	return "?"
+}
+
+// DollarPlaceholder is the placeholder style used by PostgreSQL.
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// SQLStore is an OutboxStore backed by a database/sql table with the following columns, id must be assigned in insertion order:
+//
+//	id         BIGINT PRIMARY KEY (auto increment)
+//	topic      VARCHAR NOT NULL
+//	msg_key    BLOB
+//	msg_value  BLOB
+//	headers    TEXT NOT NULL
+//	created_at TIMESTAMP NOT NULL
+//	sent_at    TIMESTAMP NULL
+//
+// Pending does not lock the rows it returns, so a table must be relayed by a single Outbox: relays running in
+// several instances of a service publish the same records. Run the relay in one instance, or elect one.
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// NewSQLStore creates a SQLStore on the given table, an empty table uses DefaultTable and a nil placeholder uses QuestionPlaceholder.
+func NewSQLStore(db *sql.DB, table string, placeholder Placeholder) *SQLStore {
+	if table == "" {
+		table = DefaultTable
+	}
+	if placeholder == nil {
+		placeholder = QuestionPlaceholder
+	}
+	return &SQLStore{db: db, table: table, placeholder: placeholder}
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Add inserts the messages using tx, a nil tx inserts them directly on the database.
+func (s *SQLStore) Add(ctx context.Context, tx *sql.Tx, msgs ...kafka.Message) error {
+	var ex execer = s.db
+	if tx != nil {
+		ex = tx
+	}
+	query := fmt.Sprintf("INSERT INTO %s (topic, msg_key, msg_value, headers, created_at) VALUES (%s, %s, %s, %s, %s)",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5))
+	now := time.Now().UTC()
+	for _, msg := range msgs {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return fmt.Errorf("outbox.SQLStore.Add: error encoding headers: %w", err)
+		}
+		if _, err := ex.ExecContext(ctx, query, msg.Topic, msg.Key, msg.Value, string(headers), now); err != nil {
+			return fmt.Errorf("outbox.SQLStore.Add: error inserting message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	query := fmt.Sprintf("SELECT id, topic, msg_key, msg_value, headers, created_at FROM %s WHERE sent_at IS NULL ORDER BY id", s.table)
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("outbox.SQLStore.Pending: error querying messages: %w", err)
+	}
+	defer rows.Close()
+	var records []Record
+	for rows.Next() {
+		var r Record
+		var headers string
+		if err := rows.Scan(&r.ID, &r.Message.Topic, &r.Message.Key, &r.Message.Value, &headers, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("outbox.SQLStore.Pending: error scanning message: %w", err)
+		}
+		if err := json.Unmarshal([]byte(headers), &r.Message.Headers); err != nil {
+			return nil, fmt.Errorf("outbox.SQLStore.Pending: error decoding headers: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("outbox.SQLStore.Pending: error reading messages: %w", err)
+	}
+	return records, nil
+}
+
+func (s *SQLStore) MarkSent(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("outbox.SQLStore.MarkSent: error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+	query := fmt.Sprintf("UPDATE %s SET sent_at = %s WHERE id = %s", s.table, s.placeholder(1), s.placeholder(2))
+	now := time.Now().UTC()
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, query, now, id); err != nil {
+			return fmt.Errorf("outbox.SQLStore.MarkSent: error updating message %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("outbox.SQLStore.MarkSent: error committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Record is a message persisted in the outbox.
+type Record struct {
+	ID        int64
+	Message   kafka.Message
+	CreatedAt time.Time
+}
+
+// OutboxStore persists outgoing messages until the relay publishes them.
+//
+// Add must write the messages using tx when it is not nil so that they are committed or rolled back together with
+// the caller's changes. Pending returns unsent records in the order they were added, stores do not have to hide the
+// records returned to one relay from another.
+type OutboxStore interface {
+	Add(ctx context.Context, tx *sql.Tx, msgs ...kafka.Message) error
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	MarkSent(ctx context.Context, ids ...int64) error
+}
+
+// MemoryStore is an in-memory OutboxStore, it ignores the transaction and is intended for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	nextID  int64
+	records []Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, tx *sql.Tx, msgs ...kafka.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, msg := range msgs {
+		s.nextID++
+		s.records = append(s.records, Record{ID: s.nextID, Message: msg, CreatedAt: now})
+	}
+	return nil
+}
+
+func (s *MemoryStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.records)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	return append([]Record(nil), s.records[:n]...), nil
+}
+
+func (s *MemoryStore) MarkSent(ctx context.Context, ids ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		sent[id] = struct{}{}
+	}
+	records := s.records[:0]
+	for _, r := range s.records {
+		if _, ok := sent[r.ID]; !ok {
+			records = append(records, r)
+		}
+	}
+	s.records = records
+	return nil
+}
+
+// Len returns the number of unsent records.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sabariramc/go-kit/kafka/consumer"
+	"github.com/sabariramc/go-kit/kafka/producer"
+	"github.com/sabariramc/go-kit/log"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	ModulePipeline = "KafkaPipeline"
+)
+
+// Transform maps a consumed message to the messages to produce, returning no messages skips the consumed message.
+type Transform func(ctx context.Context, msg *kafka.Message) ([]kafka.Message, error)
+
+type Config struct {
+	Reader    *consumer.Reader
+	Writer    *producer.Writer
+	Transform Transform
+	Log       *log.Logger
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Log: log.New(ModulePipeline),
+	}
+}
+
+func ValidateConfig(config *Config) error {
+	if config.Reader == nil {
+		return fmt.Errorf("kafka reader is not configured")
+	}
+	if config.Reader.CommitMode() != consumer.CommitOnAck {
+		return fmt.Errorf("kafka reader commit mode must be %v", consumer.CommitOnAck)
+	}
+	if config.Writer == nil {
+		return fmt.Errorf("kafka writer is not configured")
+	}
+	if config.Writer.Async {
+		return fmt.Errorf("kafka writer must be synchronous")
+	}
+	if config.Transform == nil {
+		return fmt.Errorf("transform is not configured")
+	}
+	if config.Log == nil {
+		return fmt.Errorf("logger is not configured")
+	}
+	return nil
+}
+
+type Options func(*Config) error
+
+func WithReader(reader *consumer.Reader) Options {
+	return func(c *Config) error {
+		c.Reader = reader
+		return nil
+	}
+}
+
+func WithWriter(writer *producer.Writer) Options {
+	return func(c *Config) error {
+		c.Writer = writer
+		return nil
+	}
+}
+
+func WithTransform(transform Transform) Options {
+	return func(c *Config) error {
+		c.Transform = transform
+		return nil
+	}
+}
+
+func WithLogger(logger *log.Logger) Options {
+	return func(c *Config) error {
+		c.Log = logger
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+// Package pipeline implements a consume-transform-produce loop that commits consumer offsets only after the produced messages are acknowledged.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	ck "github.com/sabariramc/go-kit/kafka"
+	"github.com/sabariramc/go-kit/kafka/consumer"
+	"github.com/sabariramc/go-kit/kafka/producer"
+	"github.com/sabariramc/go-kit/log"
+	"github.com/segmentio/kafka-go"
+)
+
+// Pipeline consumes messages from a reader, transforms them and writes the result to a writer.
+//
+// Every produced message is tagged with an idempotency key derived from the source topic, partition, offset and
+// position, so a message produced again after a crash carries the same key and can be dropped by a consumer with a dedup store.
+type Pipeline struct {
+	reader    *consumer.Reader
+	writer    *producer.Writer
+	transform Transform
+	log       *log.Logger
+}
+
+func New(opt ...Options) (*Pipeline, error) {
+	cfg := NewConfig()
+	for _, o := range opt {
+		if err := o(cfg); err != nil {
+			return nil, fmt.Errorf("kafka.Pipeline.New: error applying option: %w", err)
+		}
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("kafka.Pipeline.New: config validation error: %w", err)
+	}
+	return &Pipeline{
+		reader:    cfg.Reader,
+		writer:    cfg.Writer,
+		transform: cfg.Transform,
+		log:       cfg.Log,
+	}, nil
+}
+
+// Run polls the reader until the context is cancelled or an error occurs. A consumed message is acknowledged only after
+// the messages produced from it are written, transform and write errors stop the pipeline without acknowledging the message.
+func (p *Pipeline) Run(ctx context.Context) error {
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan *consumer.MessageWithContext, 1)
+	pollDone := make(chan error, 1)
+	go func() {
+		_, err := p.reader.Poll(pollCtx, ch)
+		pollDone <- err
+	}()
+	for {
+		select {
+		case err := <-pollDone:
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		case msg, ok := <-ch:
+			if !ok {
+				ch = nil
+				continue
+			}
+			if err := p.process(msg); err != nil {
+				cancel()
+				<-pollDone
+				return err
+			}
+		}
+	}
+}
+
+// process transforms the message, writes the result and acknowledges the message.
+func (p *Pipeline) process(msg *consumer.MessageWithContext) error {
+	out, err := p.transform(msg.Ctx, msg.Message)
+	if err != nil {
+		return fmt.Errorf("kafka.Pipeline.process: transform error: %w", err)
+	}
+	for i := range out {
+		setIdempotencyKey(&out[i], msg.Message, i)
+	}
+	if len(out) > 0 {
+		if err := p.writer.WriteMessages(msg.Ctx, out...); err != nil {
+			return fmt.Errorf("kafka.Pipeline.process: write error: %w", err)
+		}
+	}
+	p.reader.Ack(msg.Message)
+	p.log.Debug(msg.Ctx).Str("topic", msg.Topic).Int("partition", msg.Partition).Int64("offset", msg.Offset).Int("produced", len(out)).Msg("message processed")
+	return nil
+}
+
+// setIdempotencyKey sets an idempotency key derived from the source message unless the message already carries one.
+func setIdempotencyKey(msg *kafka.Message, src *kafka.Message, i int) {
+	if _, ok := consumer.IdempotencyKey(msg); ok {
+		return
+	}
+	key := src.Topic + ":" + strconv.Itoa(src.Partition) + ":" + strconv.FormatInt(src.Offset, 10) + ":" + strconv.Itoa(i)
+	msg.Headers = append(msg.Headers, kafka.Header{Key: ck.HeaderIdempotencyKey, Value: []byte(key)})
+}
@@ -26,6 +26,7 @@ type Config struct {
 	Writer *kafka.Writer
 	Hooks  []Hook
 	Tracer span.SpanOp
+	Meter  span.Meter // Meter, when set, records message counts, errors and write latency.
 }
 
 func ValidateConfig(config *Config) error {
@@ -133,6 +134,26 @@ func WithTracer(tracer Tracer) Options {
 	}
 }
 
+// WithMeter records producer metrics to the meter.
+func WithMeter(meter span.Meter) Options {
+	return func(c *Config) error {
+		c.Meter = meter
+		return nil
+	}
+}
+
+// WithIdempotency tags every message with an idempotency key generated by keyFunc and waits for all in-sync replicas to acknowledge writes.
+// A nil keyFunc uses RandomIdempotencyKey.
+func WithIdempotency(keyFunc IdempotencyKeyFunc) Options {
+	return func(c *Config) error {
+		c.Hooks = append(c.Hooks, IdempotencyHook{KeyFunc: keyFunc})
+		if c.Writer != nil {
+			c.Writer.RequiredAcks = kafka.RequireAll
+		}
+		return nil
+	}
+}
+
 func WithPlainSSLMechanism(username, password string) Options {
 	return func(c *Config) error {
 		if c.Writer != nil && c.Writer.Transport != nil {
@@ -0,0 +1,59 @@
+package producer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	ck "github.com/sabariramc/go-kit/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+// IdempotencyKeyFunc returns the idempotency key for a message.
+type IdempotencyKeyFunc func(ctx context.Context, msg *kafka.Message) string
+
+// RandomIdempotencyKey returns a new random key. The hook runs once per WriteMessages call and keeps the key of a
+// message that already carries one, so the key is unique per send, stays the same across the retries of the writer
+// and is reused when the same slice of messages is written again.
+func RandomIdempotencyKey(ctx context.Context, msg *kafka.Message) string {
+	return uuid.NewString()
+}
+
+// ContentIdempotencyKey derives the key from the topic, key and value of the message, so rebuilding the same message
+// yields the same key. Distinct events with identical topic, key and value get the same key and are deduplicated as
+// well, it only suits messages whose content identifies the event.
+func ContentIdempotencyKey(ctx context.Context, msg *kafka.Message) string {
+	h := sha256.New()
+	h.Write([]byte(msg.Topic))
+	h.Write([]byte{0})
+	h.Write(msg.Key)
+	h.Write([]byte{0})
+	h.Write(msg.Value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IdempotencyHook sets the ck.HeaderIdempotencyKey header on messages that do not carry one.
+//
+// segmentio/kafka-go does not implement the idempotent producer protocol (producer ID, epoch and sequence numbers),
+// so duplicates are detected on the consumer side with the key and a dedup store.
+type IdempotencyHook struct {
+	KeyFunc IdempotencyKeyFunc // KeyFunc generates the key, RandomIdempotencyKey when nil.
+}
+
+func (h IdempotencyHook) Run(ctx context.Context, msg *kafka.Message) error {
+	for _, header := range msg.Headers {
+		if header.Key == ck.HeaderIdempotencyKey {
+			return nil
+		}
+	}
+	keyFunc := h.KeyFunc
+	if keyFunc == nil {
+		keyFunc = RandomIdempotencyKey
+	}
+	msg.Headers = append(msg.Headers, kafka.Header{
+		Key:   ck.HeaderIdempotencyKey,
+		Value: []byte(keyFunc(ctx, msg)),
+	})
+	return nil
+}
@@ -0,0 +1,54 @@
+package producer
+
+import (
+	"context"
+	"time"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/segmentio/kafka-go"
+)
+
+// Metric names recorded by the Writer.
+const (
+	MetricMessages      = "kafka.producer.messages"
+	MetricErrors        = "kafka.producer.errors"
+	MetricWriteDuration = "kafka.producer.write.duration"
+)
+
+// writerMetrics holds the instruments of a Writer.
+type writerMetrics struct {
+	messages      span.Counter
+	errors        span.Counter
+	writeDuration span.Histogram
+}
+
+func newWriterMetrics(meter span.Meter) *writerMetrics {
+	if meter == nil {
+		meter = span.NoopMeter{}
+	}
+	return &writerMetrics{
+		messages:      meter.Counter(MetricMessages, span.UnitCount, "Number of messages written"),
+		errors:        meter.Counter(MetricErrors, span.UnitCount, "Number of messages that failed to be written"),
+		writeDuration: meter.Histogram(MetricWriteDuration, span.UnitSeconds, "Duration of WriteMessages calls"),
+	}
+}
+
+// recordWrite records the outcome of a WriteMessages call, in async mode only the enqueueing of the messages is measured.
+func (m *writerMetrics) recordWrite(ctx context.Context, msgs []kafka.Message, defaultTopic string, start time.Time, err error) {
+	m.writeDuration.Record(ctx, time.Since(start).Seconds())
+	counts := make(map[string]int64, 1)
+	for i := range msgs {
+		topic := msgs[i].Topic
+		if topic == "" {
+			topic = defaultTopic
+		}
+		counts[topic]++
+	}
+	counter := m.messages
+	if err != nil {
+		counter = m.errors
+	}
+	for topic, n := range counts {
+		counter.Add(ctx, n, span.Attr(span.MessagingDestinationName, topic))
+	}
+}
@@ -0,0 +1,46 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sabariramc/go-kit/kafka/codec"
+	"github.com/segmentio/kafka-go"
+)
+
+// TypedMessage is a message whose value is encoded by a TypedWriter.
+type TypedMessage[T any] struct {
+	Topic   string
+	Key     []byte
+	Value   T
+	Headers []kafka.Header
+	Time    time.Time
+}
+
+// TypedWriter encodes values of type T with a codec and writes them through a Writer.
+type TypedWriter[T any] struct {
+	writer *Writer
+	codec  codec.Codec
+}
+
+func NewTypedWriter[T any](writer *Writer, c codec.Codec) *TypedWriter[T] {
+	return &TypedWriter[T]{writer: writer, codec: c}
+}
+
+// WriteMessages encodes the messages and writes them, a message without a topic uses the topic configured on the writer.
+func (w *TypedWriter[T]) WriteMessages(ctx context.Context, msgs ...TypedMessage[T]) error {
+	out := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		topic := msg.Topic
+		if topic == "" {
+			topic = w.writer.Topic
+		}
+		value, err := w.codec.Encode(ctx, topic, msg.Value)
+		if err != nil {
+			return fmt.Errorf("kafka.TypedWriter.WriteMessages: error encoding message %d: %w", i, err)
+		}
+		out[i] = kafka.Message{Topic: msg.Topic, Key: msg.Key, Value: value, Headers: msg.Headers, Time: msg.Time}
+	}
+	return w.writer.WriteMessages(ctx, out...)
+}
@@ -3,6 +3,7 @@ package producer
 import (
 	"context"
 	"fmt"
+	"time"
 
 	span "github.com/sabariramc/go-kit/instrumentation"
 	"github.com/sabariramc/go-kit/log"
@@ -11,9 +12,10 @@ import (
 
 type Writer struct {
 	*kafka.Writer
-	log   *log.Logger
-	hooks []Hook
-	tr    span.SpanOp
+	log     *log.Logger
+	hooks   []Hook
+	tr      span.SpanOp
+	metrics *writerMetrics
 }
 
 func New(ctx context.Context, opt ...Options) (*Writer, error) {
@@ -28,10 +30,11 @@ func New(ctx context.Context, opt ...Options) (*Writer, error) {
 		return nil, fmt.Errorf("kafka.Writer.New: validation error: %w", err)
 	}
 	return &Writer{
-		Writer: cfg.Writer,
-		log:    cfg.Log,
-		hooks:  cfg.Hooks,
-		tr:     cfg.Tracer,
+		Writer:  cfg.Writer,
+		log:     cfg.Log,
+		hooks:   cfg.Hooks,
+		tr:      cfg.Tracer,
+		metrics: newWriterMetrics(cfg.Meter),
 	}, nil
 }
 
@@ -54,7 +57,10 @@ func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error
 		ctx, sp = w.tr.NewSpanFromContext(ctx, "kafka.write", span.SpanKindProducer, "")
 		defer sp.Finish()
 	}
-	return w.Writer.WriteMessages(ctx, msgs...)
+	start := time.Now()
+	err := w.Writer.WriteMessages(ctx, msgs...)
+	w.metrics.recordWrite(ctx, msgs, w.Writer.Topic, start, err)
+	return err
 }
 
 func (w *Writer) runHooks(ctx context.Context, msg *kafka.Message) {
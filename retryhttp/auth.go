@@ -0,0 +1,267 @@
+package retryhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEarlyRefresh is how long before its expiry a cached token is refreshed.
+const DefaultEarlyRefresh = 30 * time.Second
+
+// Authenticator authenticates a request before every attempt, an error aborts the call.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// Refresher is implemented by authenticators whose credentials can be refreshed. When a request is rejected with
+// 401, the client calls Invalidate and sends the request once more.
+type Refresher interface {
+	Invalidate()
+}
+
+// Token is an access token.
+type Token struct {
+	AccessToken string
+	TokenType   string    // TokenType is the authorization scheme of the token, Bearer when empty.
+	Expiry      time.Time // Expiry is the time the token expires, the zero time never expires.
+}
+
+// TokenSource returns access tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc is a function implementing TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource always returning the bearer token.
+func StaticTokenSource(token string) TokenSource {
+	t := &Token{AccessToken: token, TokenType: "Bearer"}
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return t, nil
+	})
+}
+
+// CachedTokenSource caches the token of a TokenSource. Tokens are refreshed in the background once they are within
+// the early refresh window of their expiry, and concurrent fetches are collapsed into a single call to the source.
+type CachedTokenSource struct {
+	source       TokenSource
+	earlyRefresh time.Duration
+	mu           sync.Mutex
+	token        *Token
+	call         *tokenCall
+}
+
+// tokenCall is an in-flight fetch of a token.
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewCachedTokenSource creates a CachedTokenSource refreshing tokens earlyRefresh before they expire.
+func NewCachedTokenSource(source TokenSource, earlyRefresh time.Duration) *CachedTokenSource {
+	return &CachedTokenSource{
+		source:       source,
+		earlyRefresh: earlyRefresh,
+	}
+}
+
+func (s *CachedTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	token := s.token
+	if token != nil {
+		if token.Expiry.IsZero() || time.Until(token.Expiry) > s.earlyRefresh {
+			s.mu.Unlock()
+			return token, nil
+		}
+		if time.Now().Before(token.Expiry) {
+			s.fetch(ctx)
+			s.mu.Unlock()
+			return token, nil
+		}
+	}
+	call := s.fetch(ctx)
+	s.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token and detaches the fetch in flight, if any, so that the next call starts a new
+// fetch instead of joining one that may return the rejected token.
+func (s *CachedTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	s.call = nil
+}
+
+// fetch starts fetching a token unless a fetch is in flight and returns the fetch, must be called with the lock held.
+// The token of a fetch detached by Invalidate is returned to its callers but not cached.
+func (s *CachedTokenSource) fetch(ctx context.Context) *tokenCall {
+	if s.call != nil {
+		return s.call
+	}
+	call := &tokenCall{done: make(chan struct{})}
+	s.call = call
+	go func() {
+		call.token, call.err = s.source.Token(context.WithoutCancel(ctx))
+		s.mu.Lock()
+		if s.call == call {
+			s.call = nil
+			if call.err == nil {
+				s.token = call.token
+			}
+		}
+		s.mu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+// TokenAuth sets the Authorization header from a cached TokenSource.
+type TokenAuth struct {
+	source *CachedTokenSource
+}
+
+// NewTokenAuth creates a TokenAuth caching the tokens of source, refreshed DefaultEarlyRefresh before they expire.
+func NewTokenAuth(source TokenSource) *TokenAuth {
+	cached, ok := source.(*CachedTokenSource)
+	if !ok {
+		cached = NewCachedTokenSource(source, DefaultEarlyRefresh)
+	}
+	return &TokenAuth{source: cached}
+}
+
+func (a *TokenAuth) Authenticate(req *http.Request) error {
+	token, err := a.source.Token(req.Context())
+	if err != nil {
+		return fmt.Errorf("TokenAuth.Authenticate: error fetching token: %w", err)
+	}
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	req.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+	return nil
+}
+
+func (a *TokenAuth) Invalidate() {
+	a.source.Invalidate()
+}
+
+// ClientCredentials is a TokenSource fetching tokens with the OAuth2 client credentials grant.
+type ClientCredentials struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	EndpointParams url.Values   // EndpointParams are additional parameters sent to the token endpoint, such as audience.
+	Client         *http.Client // Client sends the token requests, http.DefaultClient when nil.
+}
+
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	for k, v := range c.EndpointParams {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("ClientCredentials.Token: error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ClientCredentials.Token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("ClientCredentials.Token: error reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("ClientCredentials.Token: token endpoint returned %v: %s", resp.StatusCode, body)
+	}
+	var res struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("ClientCredentials.Token: error decoding response: %w", err)
+	}
+	if res.AccessToken == "" {
+		return nil, fmt.Errorf("ClientCredentials.Token: token endpoint returned no access token")
+	}
+	token := &Token{AccessToken: res.AccessToken, TokenType: res.TokenType}
+	if res.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// requestBody returns a copy of the body of the request.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// authenticate authenticates the request with the authenticator of the client.
+func (c *Client) authenticate(req *http.Request) error {
+	if c.auth == nil {
+		return nil
+	}
+	if err := c.auth.Authenticate(req); err != nil {
+		return fmt.Errorf("retryhttp.Client.Do: error authenticating request: %w", err)
+	}
+	return nil
+}
+
+// reauthenticate invalidates the credentials when the response is a 401 and the authenticator can refresh them,
+// it reports whether the request should be sent again.
+func (c *Client) reauthenticate(req *http.Request, resp *http.Response) bool {
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	refresher, ok := c.auth.(Refresher)
+	if !ok {
+		return false
+	}
+	c.log.Warn(req.Context()).Msg("request unauthorized, refreshing credentials")
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	refresher.Invalidate()
+	return true
+}
@@ -0,0 +1,341 @@
+package retryhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sabariramc/go-kit/errors"
+	"github.com/sabariramc/go-kit/log"
+)
+
+// ErrorCodeCircuitOpen is the error code of the error returned when a request is rejected by an open circuit.
+const ErrorCodeCircuitOpen = "CIRCUIT_OPEN"
+
+// BreakerState is the state of a circuit.
+type BreakerState int
+
+// Circuit states, a closed circuit lets requests through, an open circuit rejects them until the cooldown elapses
+// and a half-open circuit lets a limited number of probes through to decide whether to close or reopen.
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// KeyFunc returns the key grouping a request, such as its circuit or its rate limit.
+type KeyFunc func(req *http.Request) string
+
+// HostKey groups requests by their host.
+func HostKey(req *http.Request) string {
+	return req.URL.Host
+}
+
+// BreakerConfig contains the configuration of a CircuitBreaker.
+type BreakerConfig struct {
+	FailureRate         float64       // FailureRate opens the circuit when the ratio of failures in the window reaches it, 0 disables the check.
+	MinRequests         uint          // MinRequests is the number of outcomes required in the window before the failure rate is checked.
+	WindowSize          uint          // WindowSize is the number of latest outcomes the failure rate is computed on.
+	ConsecutiveFailures uint          // ConsecutiveFailures opens the circuit after as many failures in a row, 0 disables the check.
+	Cooldown            time.Duration // Cooldown is the time an open circuit waits before letting probes through.
+	HalfOpenProbes      uint          // HalfOpenProbes is the number of concurrent probes allowed, and successes required to close a half-open circuit.
+	Key                 KeyFunc       // Key returns the circuit of a request.
+	Log                 *log.Logger   // Log records state transitions.
+}
+
+// GetDefaultBreakerConfig returns a BreakerConfig with default settings.
+func GetDefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureRate:         0.5,
+		MinRequests:         10,
+		WindowSize:          20,
+		ConsecutiveFailures: 5,
+		Cooldown:            30 * time.Second,
+		HalfOpenProbes:      1,
+		Key:                 HostKey,
+		Log:                 log.New("CircuitBreaker"),
+	}
+}
+
+// BreakerOption represents an option function for configuring the breaker config.
+type BreakerOption func(*BreakerConfig)
+
+// WithFailureRate opens the circuit when the failure ratio of the last window outcomes reaches rate, once at least minRequests outcomes are recorded.
+func WithFailureRate(rate float64, minRequests, window uint) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.FailureRate = rate
+		cfg.MinRequests = minRequests
+		cfg.WindowSize = window
+	}
+}
+
+// WithConsecutiveFailures opens the circuit after n failures in a row.
+func WithConsecutiveFailures(n uint) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.ConsecutiveFailures = n
+	}
+}
+
+// WithCooldown sets the time an open circuit rejects requests.
+func WithCooldown(cooldown time.Duration) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.Cooldown = cooldown
+	}
+}
+
+// WithHalfOpenProbes sets the number of probes of a half-open circuit.
+func WithHalfOpenProbes(n uint) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.HalfOpenProbes = n
+	}
+}
+
+// WithBreakerKey sets the function mapping requests to circuits, for example to break per route instead of per host.
+func WithBreakerKey(key KeyFunc) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.Key = key
+	}
+}
+
+// WithBreakerLogger sets the logger of the breaker.
+func WithBreakerLogger(logger *log.Logger) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		cfg.Log = logger
+	}
+}
+
+// CircuitBreaker tracks the outcome of requests per circuit key and rejects requests to circuits that are failing.
+// A request is a failure when CheckRetry asks for it to be retried.
+type CircuitBreaker struct {
+	config   BreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of a single circuit.
+type circuit struct {
+	state       BreakerState
+	outcomes    []bool // ring buffer of the latest outcomes, true is a failure
+	next        int
+	failures    uint
+	consecutive uint
+	openedAt    time.Time
+	probes      uint
+	successes   uint
+}
+
+// NewCircuitBreaker creates a CircuitBreaker with the given options.
+func NewCircuitBreaker(options ...BreakerOption) *CircuitBreaker {
+	config := GetDefaultBreakerConfig()
+	for _, opt := range options {
+		opt(&config)
+	}
+	if config.WindowSize == 0 {
+		config.WindowSize = 1
+	}
+	if config.HalfOpenProbes == 0 {
+		config.HalfOpenProbes = 1
+	}
+	return &CircuitBreaker{
+		config:   config,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// State returns the state of the circuit of key.
+func (b *CircuitBreaker) State(key string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return StateClosed
+	}
+	b.refresh(context.Background(), key, c)
+	return c.state
+}
+
+// States returns the state of every known circuit.
+func (b *CircuitBreaker) States() map[string]BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make(map[string]BreakerState, len(b.circuits))
+	for key, c := range b.circuits {
+		b.refresh(context.Background(), key, c)
+		states[key] = c.state
+	}
+	return states
+}
+
+// HealthCheck fails when a circuit is open.
+func (b *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	open := make([]string, 0)
+	for key, state := range b.States() {
+		if state == StateOpen {
+			open = append(open, key)
+		}
+	}
+	if len(open) == 0 {
+		return nil
+	}
+	sort.Strings(open)
+	return fmt.Errorf("CircuitBreaker.HealthCheck: circuit open for %v", strings.Join(open, ", "))
+}
+
+// allow returns a CIRCUIT_OPEN error when the circuit of the request rejects it.
+func (b *CircuitBreaker) allow(req *http.Request) error {
+	if b == nil {
+		return nil
+	}
+	key := b.config.Key(req)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(key)
+	b.refresh(req.Context(), key, c)
+	switch c.state {
+	case StateOpen:
+		return newCircuitOpenError(key)
+	case StateHalfOpen:
+		if c.probes >= b.config.HalfOpenProbes {
+			return newCircuitOpenError(key)
+		}
+		c.probes++
+	}
+	return nil
+}
+
+// record records the outcome of an allowed request, outcomes of canceled requests only release the probe.
+func (b *CircuitBreaker) record(req *http.Request, failed bool) {
+	if b == nil {
+		return
+	}
+	key := b.config.Key(req)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(key)
+	switch c.state {
+	case StateHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if req.Context().Err() != nil {
+			return
+		}
+		if failed {
+			b.transition(req.Context(), key, c, StateOpen)
+			return
+		}
+		c.successes++
+		if c.successes >= b.config.HalfOpenProbes {
+			b.transition(req.Context(), key, c, StateClosed)
+		}
+	case StateClosed:
+		if req.Context().Err() != nil {
+			return
+		}
+		c.add(failed, b.config.WindowSize)
+		if b.tripped(c) {
+			b.transition(req.Context(), key, c, StateOpen)
+		}
+	}
+}
+
+// release gives back the probe of an allowed request that was not sent.
+func (b *CircuitBreaker) release(req *http.Request) {
+	if b == nil {
+		return
+	}
+	key := b.config.Key(req)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok && c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// tripped reports whether a closed circuit crossed one of the thresholds.
+func (b *CircuitBreaker) tripped(c *circuit) bool {
+	if b.config.ConsecutiveFailures > 0 && c.consecutive >= b.config.ConsecutiveFailures {
+		return true
+	}
+	total := uint(len(c.outcomes))
+	return b.config.FailureRate > 0 && total >= b.config.MinRequests && float64(c.failures)/float64(total) >= b.config.FailureRate
+}
+
+// circuit returns the circuit of key, creating it when missing. Must be called with the lock held.
+func (b *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{outcomes: make([]bool, 0, b.config.WindowSize)}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+// refresh moves an open circuit to half-open once the cooldown elapsed. Must be called with the lock held.
+func (b *CircuitBreaker) refresh(ctx context.Context, key string, c *circuit) {
+	if c.state == StateOpen && time.Since(c.openedAt) >= b.config.Cooldown {
+		b.transition(ctx, key, c, StateHalfOpen)
+	}
+}
+
+// transition changes the state of a circuit and resets the counters of the new state. Must be called with the lock held.
+func (b *CircuitBreaker) transition(ctx context.Context, key string, c *circuit, state BreakerState) {
+	b.config.Log.Warn(ctx).Str("circuit", key).Str("from", c.state.String()).Str("to", state.String()).Msgf("circuit %v is %v", key, state)
+	c.state = state
+	c.probes = 0
+	c.successes = 0
+	switch state {
+	case StateOpen:
+		c.openedAt = time.Now()
+	case StateClosed:
+		c.outcomes = c.outcomes[:0]
+		c.next = 0
+		c.failures = 0
+		c.consecutive = 0
+	}
+}
+
+// add appends an outcome to the window.
+func (c *circuit) add(failed bool, size uint) {
+	if uint(len(c.outcomes)) < size {
+		c.outcomes = append(c.outcomes, failed)
+	} else {
+		if c.outcomes[c.next] {
+			c.failures--
+		}
+		c.outcomes[c.next] = failed
+		c.next = (c.next + 1) % len(c.outcomes)
+	}
+	if failed {
+		c.failures++
+		c.consecutive++
+	} else {
+		c.consecutive = 0
+	}
+}
+
+func newCircuitOpenError(key string) *errors.HTTPError {
+	return &errors.HTTPError{
+		StatusCode: http.StatusServiceUnavailable,
+		Err: &errors.Error{
+			Code:    ErrorCodeCircuitOpen,
+			Message: "circuit open for " + key,
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package retryhttp
+
+import "sync"
+
+// RetryBudget is a token bucket shared by the requests of a Client that caps retries to a ratio of successful calls.
+// Every successful call deposits ratio tokens and every retry withdraws one, retries are skipped once the bucket is empty.
+type RetryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	max    float64
+	tokens float64
+}
+
+// NewRetryBudget creates a budget allowing percent retries for every 100 successful calls, with up to burst retries
+// available before any call succeeds.
+func NewRetryBudget(percent float64, burst uint) *RetryBudget {
+	return &RetryBudget{
+		ratio:  percent / 100,
+		max:    float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Available returns the number of retries currently available.
+func (b *RetryBudget) Available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// deposit credits the budget for a successful call.
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.max)
+}
+
+// withdraw takes a token for a retry, it returns false when the budget is exhausted.
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,118 @@
+package retryhttp
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the size limit of the default in-memory cache.
+const DefaultCacheSize = 64 << 20
+
+// CacheEntry is a stored response.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Vary       map[string]string // Vary holds the request header values named by the Vary header of the response.
+	StoredAt   time.Time         // StoredAt is the time the response was received or last revalidated.
+}
+
+// size returns the approximate memory used by the entry.
+func (e *CacheEntry) size() int64 {
+	n := int64(len(e.Body))
+	for k, v := range e.Header {
+		n += int64(len(k))
+		for _, s := range v {
+			n += int64(len(s))
+		}
+	}
+	for k, v := range e.Vary {
+		n += int64(len(k) + len(v))
+	}
+	return n
+}
+
+// Cache stores responses for the caching layer of the Client.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CacheEntry, bool)
+	Set(ctx context.Context, key string, entry *CacheEntry)
+	Delete(ctx context.Context, key string)
+}
+
+// MemoryCache is a Cache that evicts the least recently used entries once the size of the entries exceeds a limit.
+type MemoryCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	order   *list.List
+	items   map[string]*list.Element
+}
+
+// memoryItem is an element of the MemoryCache LRU list.
+type memoryItem struct {
+	key   string
+	entry *CacheEntry
+	size  int64
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxSize bytes of responses.
+func NewMemoryCache(maxSize int64) *MemoryCache {
+	return &MemoryCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryItem).entry, true
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, entry *CacheEntry) {
+	size := entry.size()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	if size > m.maxSize {
+		return
+	}
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, entry: entry, size: size})
+	m.size += size
+	for m.size > m.maxSize {
+		m.remove(m.order.Back())
+	}
+}
+
+func (m *MemoryCache) Delete(ctx context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove deletes an element, must be called with the lock held.
+func (m *MemoryCache) remove(el *list.Element) {
+	item := m.order.Remove(el).(*memoryItem)
+	delete(m.items, item.key)
+	m.size -= item.size
+}
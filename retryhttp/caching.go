@@ -0,0 +1,346 @@
+package retryhttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Results of a cache lookup, recorded as the AttrCacheResult attribute.
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheStale       = "stale"
+	CacheRevalidated = "revalidated"
+)
+
+// cacheableStatus lists the status codes stored by the cache.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// responseCache is the caching layer of a Client.
+type responseCache struct {
+	store        Cache
+	revalidating sync.Map
+}
+
+// cacheControl holds the Cache-Control directives understood by the cache.
+type cacheControl struct {
+	noStore              bool
+	noCache              bool
+	public               bool
+	private              bool
+	mustRevalidate       bool
+	maxAge               time.Duration
+	hasMaxAge            bool
+	staleWhileRevalidate time.Duration
+}
+
+// parseCacheControl parses the Cache-Control header.
+func parseCacheControl(header http.Header) cacheControl {
+	var cc cacheControl
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		value = strings.Trim(value, "\"")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "public":
+			cc.public = true
+		case "private":
+			cc.private = true
+		case "must-revalidate", "proxy-revalidate":
+			cc.mustRevalidate = true
+		case "max-age":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				cc.maxAge = time.Duration(seconds) * time.Second
+				cc.hasMaxAge = true
+			}
+		case "stale-while-revalidate":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				cc.staleWhileRevalidate = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return cc
+}
+
+// cacheKey returns the key of a GET request.
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// isCacheable reports whether the request can be served from the cache.
+func isCacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet || parseCacheControl(req.Header).noStore {
+		return false
+	}
+	return req.Header.Get("Range") == "" && req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == ""
+}
+
+// credentialed reports whether the request carries credentials, either set by the caller or added by the
+// authenticator of the client after the cache lookup.
+func (c *Client) credentialed(req *http.Request) bool {
+	return c.auth != nil || req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+// freshness returns the freshness lifetime of the entry.
+func (e *CacheEntry) freshness() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if cc.hasMaxAge {
+		return cc.maxAge
+	}
+	expires, err := http.ParseTime(e.Header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.StoredAt
+	}
+	return expires.Sub(date)
+}
+
+// age returns the current age of the entry.
+func (e *CacheEntry) age() time.Duration {
+	age := time.Since(e.StoredAt)
+	if seconds, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	return age
+}
+
+// matches reports whether the request selects the same variant as the entry.
+func (e *CacheEntry) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// response builds a response from the entry.
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(e.age().Seconds()), 10))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// newCacheEntry returns the entry of the response without its body, nil when the response must not be stored.
+// The cache is shared by every caller of the client, so private responses are never stored and responses to
+// credentialed requests only when they are public.
+func newCacheEntry(req *http.Request, resp *http.Response, credentialed bool) *CacheEntry {
+	cc := parseCacheControl(resp.Header)
+	if !cacheableStatus[resp.StatusCode] || cc.noStore || cc.private || parseCacheControl(req.Header).noStore {
+		return nil
+	}
+	if credentialed && !cc.public {
+		return nil
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" && !cc.hasMaxAge && resp.Header.Get("Expires") == "" {
+		return nil
+	}
+	vary := make(map[string]string)
+	for _, v := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name != "" {
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Get(name)
+			}
+		}
+	}
+	return &CacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Vary:       vary,
+		StoredAt:   time.Now(),
+	}
+}
+
+// cacheBody copies a response body as it is read and stores it once read to the end. Storing is abandoned when the
+// body exceeds limit, fails or is closed before the end, so the body is never buffered beyond limit.
+type cacheBody struct {
+	io.ReadCloser
+	buf     bytes.Buffer
+	limit   int64
+	done    bool
+	store   func(body []byte)
+	abandon func()
+}
+
+func (b *cacheBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.done {
+		return n, err
+	}
+	if int64(b.buf.Len()+n) > b.limit {
+		b.done = true
+		b.buf = bytes.Buffer{}
+		b.abandon()
+		return n, err
+	}
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.done = true
+		b.store(b.buf.Bytes())
+	} else if err != nil {
+		b.done = true
+	}
+	return n, err
+}
+
+func (b *cacheBody) Close() error {
+	b.done = true
+	return b.ReadCloser.Close()
+}
+
+// setValidators adds the conditional headers of the entry to the request.
+func setValidators(req *http.Request, entry *CacheEntry) {
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := entry.Header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+}
+
+// refresh updates the entry with the headers of a 304 response.
+func (e *CacheEntry) refresh(resp *http.Response) *CacheEntry {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	for _, name := range []string{"Cache-Control", "Expires", "Date", "ETag", "Last-Modified", "Vary"} {
+		if v := resp.Header.Values(name); len(v) > 0 {
+			updated.Header[name] = v
+		}
+	}
+	updated.Header.Del("Age")
+	updated.StoredAt = time.Now()
+	return &updated
+}
+
+// doCached serves GET requests from the cache, revalidating stale entries with the origin. Credentialed requests
+// are only served public entries.
+func (c *Client) doCached(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := cacheKey(req)
+	entry, ok := c.cache.store.Get(ctx, key)
+	if ok && (!entry.matches(req) || c.credentialed(req) && !parseCacheControl(entry.Header).public) {
+		entry, ok = nil, false
+	}
+	if ok {
+		cc := parseCacheControl(entry.Header)
+		reqCC := parseCacheControl(req.Header)
+		age, lifetime := entry.age(), entry.freshness()
+		if !cc.noCache && !reqCC.noCache {
+			if age < lifetime {
+				c.logCache(req, CacheHit)
+				return entry.response(req), nil
+			}
+			if !cc.mustRevalidate && age < lifetime+cc.staleWhileRevalidate {
+				c.logCache(req, CacheStale)
+				c.revalidate(req, key, entry)
+				return entry.response(req), nil
+			}
+		}
+		req = req.Clone(ctx)
+		setValidators(req, entry)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		entry = entry.refresh(resp)
+		c.cache.store.Set(ctx, key, entry)
+		c.logCache(req, CacheRevalidated)
+		return entry.response(req), nil
+	}
+	c.logCache(req, CacheMiss)
+	c.store(req, key, resp)
+	return resp, nil
+}
+
+// store saves the response in the cache when it is cacheable. The body is stored as the caller reads it, responses
+// larger than the maximum response size of the client or not read to the end are not stored.
+func (c *Client) store(req *http.Request, key string, resp *http.Response) {
+	entry := newCacheEntry(req, resp, c.credentialed(req))
+	if entry == nil || resp.ContentLength > c.maxResponseSize {
+		if req.Method == http.MethodGet && resp.StatusCode < 500 {
+			c.cache.store.Delete(req.Context(), key)
+		}
+		return
+	}
+	ctx := context.WithoutCancel(req.Context())
+	resp.Body = &cacheBody{
+		ReadCloser: resp.Body,
+		limit:      c.maxResponseSize,
+		store: func(body []byte) {
+			entry.Body = body
+			c.cache.store.Set(ctx, key, entry)
+		},
+		abandon: func() {
+			c.cache.store.Delete(ctx, key)
+		},
+	}
+}
+
+// revalidate refreshes a stale entry in the background, concurrent revalidations of the same key are skipped.
+func (c *Client) revalidate(req *http.Request, key string, entry *CacheEntry) {
+	if _, loaded := c.cache.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	revalidation := req.Clone(context.WithoutCancel(req.Context()))
+	setValidators(revalidation, entry)
+	go func() {
+		defer c.cache.revalidating.Delete(key)
+		resp, err := c.do(revalidation)
+		if err != nil {
+			c.log.Warn(revalidation.Context()).Err(err).Msg("background revalidation failed")
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotModified {
+			c.cache.store.Set(revalidation.Context(), key, entry.refresh(resp))
+			return
+		}
+		c.store(revalidation, key, resp)
+		io.Copy(io.Discard, io.LimitReader(resp.Body, c.maxResponseSize+1))
+	}()
+}
+
+// logCache logs and records the result of a cache lookup.
+func (c *Client) logCache(req *http.Request, result string) {
+	c.log.Debug(req.Context()).Str("cache", result).Str("url", req.URL.Redacted()).Msg("http cache lookup")
+	c.metrics.recordCache(req, result)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	span "github.com/sabariramc/go-kit/instrumentation"
 	"github.com/sabariramc/go-kit/log"
 	"github.com/sabariramc/go-kit/log/correlation"
 )
@@ -33,14 +34,24 @@ func EventCorrelation(req *http.Request) {
 // Config contains the configuration settings for the HTTP client, including logging,
 // retry policies, backoff strategies, and the HTTP client itself.
 type Config struct {
-	RetryMax     uint          // RetryMax is the maximum number of retry attempts for failed requests.
-	MinRetryWait time.Duration // MinRetryWait is the minimum duration to wait before retrying a failed request.
-	MaxRetryWait time.Duration // MaxRetryWait is the maximum duration to wait before retrying a failed request.
-	CheckRetry   CheckRetry    // CheckRetry is the function to determine if a request should be retried.
-	Backoff      Backoff       // Backoff is the function to determine the wait duration between retries.
-	Client       *http.Client  // Client is the underlying HTTP client used to make requests.
-	Log          *log.Logger   // Logger for the HTTP client
-	Hook         []Hook        // Hooks are functions that can be executed before making a request.
+	RetryMax           uint            // RetryMax is the maximum number of retry attempts for failed requests.
+	MinRetryWait       time.Duration   // MinRetryWait is the minimum duration to wait before retrying a failed request.
+	MaxRetryWait       time.Duration   // MaxRetryWait is the maximum duration to wait before retrying a failed request.
+	CheckRetry         CheckRetry      // CheckRetry is the function to determine if a request should be retried.
+	Backoff            Backoff         // Backoff is the function to determine the wait duration between retries.
+	Client             *http.Client    // Client is the underlying HTTP client used to make requests.
+	Log                *log.Logger     // Logger for the HTTP client
+	Hook               []Hook          // Hooks are functions that can be executed before making a request.
+	Meter              span.Meter      // Meter records attempts, retries, give-ups and request latency, nil disables metrics.
+	Tracer             span.SpanOp     // Tracer creates a span per call and per attempt, it injects trace headers when it implements span.Propagator.
+	Breaker            *CircuitBreaker // Breaker rejects requests to failing hosts with a CIRCUIT_OPEN error, nil disables it.
+	RetryBudget        *RetryBudget    // RetryBudget caps retries to a ratio of successful calls, nil allows every retry.
+	RetryNonIdempotent bool            // RetryNonIdempotent retries POST, PATCH and other non-idempotent requests like idempotent ones.
+	MaxResponseSize    int64           // MaxResponseSize limits the response bodies read by DoJSON and GetJSON and stored by the cache.
+	Hedger             *Hedger         // Hedger sends a second request when an idempotent attempt is slow, nil disables hedging.
+	Cache              Cache           // Cache stores GET responses following their Cache-Control, ETag and Last-Modified headers, nil disables caching.
+	Limiter            *RateLimiter    // Limiter limits the rate of attempts per host, nil disables rate limiting.
+	Auth               Authenticator   // Auth authenticates every attempt, nil sends requests as they are.
 }
 
 // newDefaultHTTPClient creates and configures a new HTTP client with custom transport settings.
@@ -58,14 +69,15 @@ func newDefaultHTTPClient() *http.Client {
 // GetDefaultConfig returns a Config instance with default settings for the HTTP client.
 func GetDefaultConfig() Config {
 	return Config{
-		RetryMax:     4,                                // Sets the maximum number of retry attempts to 4.
-		MinRetryWait: time.Millisecond * 10,            // Sets the minimum retry wait duration to 10 milliseconds.
-		MaxRetryWait: time.Second * 5,                  // Sets the maximum retry wait duration to 5 seconds.
-		CheckRetry:   retryablehttp.DefaultRetryPolicy, // Uses the default retry policy.
-		Backoff:      retryablehttp.DefaultBackoff,     // Uses the default backoff strategy.
-		Client:       newDefaultHTTPClient(),           // Uses a custom HTTP client with specific transport settings.
-		Log:          log.New("HttpClient"),
-		Hook:         []Hook{HookFunc(EventCorrelation)}, // Initializes the hooks with the EventCorrelation function.
+		RetryMax:        4,                                // Sets the maximum number of retry attempts to 4.
+		MinRetryWait:    time.Millisecond * 10,            // Sets the minimum retry wait duration to 10 milliseconds.
+		MaxRetryWait:    time.Second * 5,                  // Sets the maximum retry wait duration to 5 seconds.
+		CheckRetry:      retryablehttp.DefaultRetryPolicy, // Uses the default retry policy.
+		Backoff:         retryablehttp.DefaultBackoff,     // Uses the default backoff strategy.
+		Client:          newDefaultHTTPClient(),           // Uses a custom HTTP client with specific transport settings.
+		Log:             log.New("HttpClient"),
+		MaxResponseSize: DefaultMaxResponseSize,             // Limits the response bodies read by the JSON helpers and cached to 10MB.
+		Hook:            []Hook{HookFunc(EventCorrelation)}, // Initializes the hooks with the EventCorrelation function.
 	}
 }
 
@@ -83,3 +95,96 @@ func WithNewHooks(hook ...Hook) Option {
 		cfg.Hook = hook
 	}
 }
+
+// WithMeter records client metrics to the meter.
+func WithMeter(meter span.Meter) Option {
+	return func(cfg *Config) {
+		cfg.Meter = meter
+	}
+}
+
+// WithTracer creates a client span for every call with a child span for every attempt. When the tracer implements
+// span.Propagator the trace context of the attempt span is injected into the request headers.
+func WithTracer(tracer span.SpanOp) Option {
+	return func(cfg *Config) {
+		cfg.Tracer = tracer
+	}
+}
+
+// WithCircuitBreaker fails requests fast while the circuit of their host is open. A breaker can be shared by clients
+// and registered as a health check.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(cfg *Config) {
+		cfg.Breaker = breaker
+	}
+}
+
+// WithRetryBudget caps the retries of the client to the budget.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(cfg *Config) {
+		cfg.RetryBudget = budget
+	}
+}
+
+// WithIdempotencyKey sets an idempotency key generated by keyFunc on non-idempotent requests, which makes them safe
+// to retry. A nil keyFunc uses RandomIdempotencyKey.
+func WithIdempotencyKey(keyFunc IdempotencyKeyFunc) Option {
+	return func(cfg *Config) {
+		cfg.Hook = append(cfg.Hook, IdempotencyHook{KeyFunc: keyFunc})
+	}
+}
+
+// WithRetryNonIdempotent retries non-idempotent requests without an idempotency key.
+func WithRetryNonIdempotent() Option {
+	return func(cfg *Config) {
+		cfg.RetryNonIdempotent = true
+	}
+}
+
+// WithMaxResponseSize limits the response bodies read by DoJSON and GetJSON and stored by the cache to size bytes.
+func WithMaxResponseSize(size int64) Option {
+	return func(cfg *Config) {
+		cfg.MaxResponseSize = size
+	}
+}
+
+// WithHedging hedges slow attempts of idempotent requests with the hedger.
+func WithHedging(hedger *Hedger) Option {
+	return func(cfg *Config) {
+		cfg.Hedger = hedger
+	}
+}
+
+// WithCache caches GET responses in cache, a nil cache uses a MemoryCache of DefaultCacheSize. The cache is shared by
+// every caller of the client: private responses are not cached and credentialed requests only use public responses.
+// Responses are stored once their body is read to the end and only up to the maximum response size.
+func WithCache(cache Cache) Option {
+	return func(cfg *Config) {
+		if cache == nil {
+			cache = NewMemoryCache(DefaultCacheSize)
+		}
+		cfg.Cache = cache
+	}
+}
+
+// WithRateLimiter limits the rate of attempts with the limiter, a limiter can be shared by clients calling the same API.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(cfg *Config) {
+		cfg.Limiter = limiter
+	}
+}
+
+// WithAuth authenticates every attempt with auth. When auth implements Refresher, a 401 response invalidates the
+// credentials and the request is sent once more.
+func WithAuth(auth Authenticator) Option {
+	return func(cfg *Config) {
+		cfg.Auth = auth
+	}
+}
+
+// WithHTTPClient sends requests with client, for example a client using a mock transport in tests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *Config) {
+		cfg.Client = client
+	}
+}
@@ -3,14 +3,16 @@ module github.com/sabariramc/go-kit/retryhttp
 go 1.24.4
 
 require (
+	github.com/google/uuid v1.6.0
 	github.com/hashicorp/go-retryablehttp v0.7.8
+	github.com/sabariramc/go-kit/errors v1.0.2
+	github.com/sabariramc/go-kit/instrumentation v1.0.2
 	github.com/sabariramc/go-kit/log v1.3.1
 	gotest.tools/v3 v3.5.2
 )
 
 require (
 	github.com/google/go-cmp v0.5.9 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -18,3 +20,7 @@ require (
 	github.com/sabariramc/go-kit/env v1.0.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 )
+
+replace github.com/sabariramc/go-kit/instrumentation => ../instrumentation
+
+replace github.com/sabariramc/go-kit/errors => ../errors
@@ -0,0 +1,208 @@
+package retryhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HedgeConfig contains the configuration of a Hedger.
+type HedgeConfig struct {
+	Delay      time.Duration // Delay is the fixed hedge delay, and the delay used until a host has MinSamples latencies when Percentile is set.
+	Percentile float64       // Percentile computes the hedge delay from the latency of the host, for example 0.95, 0 uses the fixed Delay.
+	WindowSize uint          // WindowSize is the number of latest latencies kept per host.
+	MinSamples uint          // MinSamples is the number of latencies required before the percentile is used.
+	Budget     *RetryBudget  // Budget caps hedged requests to a ratio of calls, nil allows every hedge.
+}
+
+// GetDefaultHedgeConfig returns a HedgeConfig with default settings, hedging at the 95th percentile latency of the
+// host for at most 10% of the calls.
+func GetDefaultHedgeConfig() HedgeConfig {
+	return HedgeConfig{
+		Delay:      100 * time.Millisecond,
+		Percentile: 0.95,
+		WindowSize: 100,
+		MinSamples: 20,
+		Budget:     NewRetryBudget(10, 10),
+	}
+}
+
+// HedgeOption represents an option function for configuring the hedge config.
+type HedgeOption func(*HedgeConfig)
+
+// WithHedgeDelay hedges requests that did not complete within delay.
+func WithHedgeDelay(delay time.Duration) HedgeOption {
+	return func(cfg *HedgeConfig) {
+		cfg.Delay = delay
+		cfg.Percentile = 0
+	}
+}
+
+// WithHedgePercentile hedges requests that did not complete within the percentile of the latest window latencies
+// of the host, once at least minSamples latencies are recorded.
+func WithHedgePercentile(percentile float64, window, minSamples uint) HedgeOption {
+	return func(cfg *HedgeConfig) {
+		cfg.Percentile = percentile
+		cfg.WindowSize = window
+		cfg.MinSamples = minSamples
+	}
+}
+
+// WithHedgeBudget caps hedged requests to the budget.
+func WithHedgeBudget(budget *RetryBudget) HedgeOption {
+	return func(cfg *HedgeConfig) {
+		cfg.Budget = budget
+	}
+}
+
+// Hedger sends a second request when an attempt of an idempotent request is slower than the hedge delay and uses
+// whichever response arrives first, the other request is cancelled.
+type Hedger struct {
+	config    HedgeConfig
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the latest latencies of a host.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// hedgeResult is the outcome of one of the requests of a hedged attempt.
+type hedgeResult struct {
+	resp    *http.Response
+	err     error
+	index   int
+	latency time.Duration
+}
+
+// NewHedger creates a Hedger with the given options.
+func NewHedger(options ...HedgeOption) *Hedger {
+	config := GetDefaultHedgeConfig()
+	for _, opt := range options {
+		opt(&config)
+	}
+	if config.WindowSize == 0 {
+		config.WindowSize = 1
+	}
+	return &Hedger{
+		config:    config,
+		latencies: make(map[string]*latencyWindow),
+	}
+}
+
+// Delay returns the current hedge delay of host.
+func (h *Hedger) Delay(host string) time.Duration {
+	if h.config.Percentile <= 0 {
+		return h.config.Delay
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w, ok := h.latencies[host]
+	if !ok || uint(len(w.samples)) < h.config.MinSamples || len(w.samples) == 0 {
+		return h.config.Delay
+	}
+	sorted := slices.Clone(w.samples)
+	slices.Sort(sorted)
+	i := int(float64(len(sorted))*h.config.Percentile+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+// observe records the latency of a completed request.
+func (h *Hedger) observe(host string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w, ok := h.latencies[host]
+	if !ok {
+		w = &latencyWindow{samples: make([]time.Duration, 0, h.config.WindowSize)}
+		h.latencies[host] = w
+	}
+	if uint(len(w.samples)) < h.config.WindowSize {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % len(w.samples)
+}
+
+// do sends the request and hedges it after the hedge delay of the host, body is the request body to replay.
+func (h *Hedger) do(client *http.Client, req *http.Request, body []byte) (*http.Response, error) {
+	h.config.Budget.deposit()
+	results := make(chan hedgeResult, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	send := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		cancels = append(cancels, cancel)
+		go func(index int) {
+			start := time.Now()
+			resp, err := client.Do(r.WithContext(ctx))
+			results <- hedgeResult{resp: resp, err: err, index: index, latency: time.Since(start)}
+		}(len(cancels) - 1)
+	}
+	send(req)
+	inflight := 1
+	timer := time.NewTimer(h.Delay(req.URL.Host))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if !h.config.Budget.withdraw() {
+				continue
+			}
+			hedged := req.Clone(req.Context())
+			if req.ContentLength > 0 {
+				hedged.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			send(hedged)
+			inflight++
+		case res := <-results:
+			inflight--
+			if res.err != nil && inflight > 0 {
+				cancels[res.index]()
+				continue
+			}
+			if res.err == nil {
+				h.observe(req.URL.Host, res.latency)
+			}
+			for i, cancel := range cancels {
+				if i != res.index {
+					cancel()
+				}
+			}
+			if inflight > 0 {
+				go drain(results)
+			}
+			if res.resp == nil {
+				cancels[res.index]()
+				return nil, res.err
+			}
+			res.resp.Body = &cancelBody{ReadCloser: res.resp.Body, cancel: cancels[res.index]}
+			return res.resp, res.err
+		}
+	}
+}
+
+// drain releases the response of the request that lost the race.
+func drain(results chan hedgeResult) {
+	res := <-results
+	if res.resp != nil {
+		res.resp.Body.Close()
+	}
+}
+
+// cancelBody cancels the context of the winning request once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
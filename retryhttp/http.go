@@ -3,12 +3,14 @@ package retryhttp
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	span "github.com/sabariramc/go-kit/instrumentation"
 	"github.com/sabariramc/go-kit/log"
 )
 
@@ -22,15 +24,43 @@ func (h HookFunc) Run(req *http.Request) {
 	h(req)
 }
 
+// ErrorHook is a Hook that can abort the call, RunE is called instead of Run and an error is returned by Do.
+type ErrorHook interface {
+	Hook
+	RunE(req *http.Request) error
+}
+
+// ErrorHookFunc is a function implementing ErrorHook.
+type ErrorHookFunc func(req *http.Request) error
+
+func (h ErrorHookFunc) Run(req *http.Request) {
+	h(req)
+}
+
+func (h ErrorHookFunc) RunE(req *http.Request) error {
+	return h(req)
+}
+
 type Client struct {
 	*http.Client
-	log          *log.Logger
-	retryMax     uint
-	minRetryWait time.Duration
-	maxRetryWait time.Duration
-	checkRetry   CheckRetry
-	backoff      Backoff
-	hooks        []Hook
+	log                *log.Logger
+	retryMax           uint
+	minRetryWait       time.Duration
+	maxRetryWait       time.Duration
+	checkRetry         CheckRetry
+	backoff            Backoff
+	hooks              []Hook
+	metrics            *clientMetrics
+	tr                 span.SpanOp
+	propagator         span.Propagator
+	breaker            *CircuitBreaker
+	budget             *RetryBudget
+	retryNonIdempotent bool
+	maxResponseSize    int64
+	hedger             *Hedger
+	cache              *responseCache
+	limiter            *RateLimiter
+	auth               Authenticator
 }
 
 func New(options ...Option) *Client {
@@ -38,14 +68,31 @@ func New(options ...Option) *Client {
 	for _, opt := range options {
 		opt(&config)
 	}
+	propagator, _ := config.Tracer.(span.Propagator)
+	var cache *responseCache
+	if config.Cache != nil {
+		cache = &responseCache{store: config.Cache}
+	}
 	return &Client{
-		Client:       config.Client,
-		retryMax:     config.RetryMax,
-		minRetryWait: config.MinRetryWait,
-		maxRetryWait: config.MaxRetryWait,
-		checkRetry:   config.CheckRetry,
-		backoff:      config.Backoff,
-		log:          config.Log,
+		Client:             config.Client,
+		retryMax:           config.RetryMax,
+		minRetryWait:       config.MinRetryWait,
+		maxRetryWait:       config.MaxRetryWait,
+		checkRetry:         config.CheckRetry,
+		backoff:            config.Backoff,
+		log:                config.Log,
+		hooks:              config.Hook,
+		metrics:            newClientMetrics(config.Meter),
+		tr:                 config.Tracer,
+		propagator:         propagator,
+		breaker:            config.Breaker,
+		budget:             config.RetryBudget,
+		retryNonIdempotent: config.RetryNonIdempotent,
+		maxResponseSize:    config.MaxResponseSize,
+		hedger:             config.Hedger,
+		cache:              cache,
+		limiter:            config.Limiter,
+		auth:               config.Auth,
 	}
 }
 func (c *Client) Get(ctx context.Context, url string) (resp *http.Response, err error) {
@@ -78,36 +125,81 @@ func (c *Client) Head(ctx context.Context, url string) (resp *http.Response, err
 }
 
 // Do sends an HTTP request and performs retries with exponential backoff as needed,
-// based on the retry and backoff configuration.
+// based on the retry and backoff configuration. GET requests are served from the cache when caching is enabled.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.cache != nil && isCacheable(req) {
+		return c.doCached(req)
+	}
+	return c.do(req)
+}
+
+// do sends the request to the origin with retries.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
 	/*this is a modified version of go-retryablehttp*/
 	var resp *http.Response
 	var attempt int
 	var shouldRetry bool
 	var doErr, respErr error
 	var reqBody []byte
+	start := time.Now()
 	if req.ContentLength > 0 {
 		reqBody, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(reqBody)), nil
+		}
 	}
 	for _, hook := range c.hooks {
 		c.log.Debug(req.Context()).Msgf("Executing hook before request: %T", hook)
+		if h, ok := hook.(ErrorHook); ok {
+			if err := h.RunE(req); err != nil {
+				return nil, fmt.Errorf("retryhttp.Client.Do: hook %T failed: %w", hook, err)
+			}
+			continue
+		}
 		hook.Run(req)
 	}
+	ctx, reqSpan := c.startRequestSpan(req)
+	var wait time.Duration
+	reauthenticated := false
 	for i := 0; ; i++ {
 		doErr = nil
 		attempt++
-		if req.ContentLength > 0 {
-			req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		limited, err := c.admit(req)
+		var written bool
+		if err == nil {
+			resp, written, doErr = c.attempt(ctx, req, reqBody, attempt, wait, limited)
+		}
+		if err == nil && !reauthenticated && c.reauthenticate(req, resp) {
+			reauthenticated = true
+			c.breaker.release(req)
+			attempt++
+			if limited, err = c.admit(req); err == nil {
+				resp, written, doErr = c.attempt(ctx, req, reqBody, attempt, 0, limited)
+			}
 		}
-		resp, doErr = c.Client.Do(req)
-		shouldRetry, respErr = c.backOffAndRetry(i, req, resp, doErr)
+		if err != nil {
+			if resp != nil {
+				resp.Body.Close()
+				resp = nil
+			}
+			doErr, respErr, shouldRetry = nil, err, false
+			break
+		}
+		shouldRetry, wait, respErr = c.backOffAndRetry(i, req, resp, doErr, written)
 		if !shouldRetry {
 			break
 		}
+		c.metrics.recordRetry(req)
+	}
+	if reqSpan != nil {
+		reqSpan.SetAttribute(AttrAttempts, attempt)
 	}
 
 	// this is the closest we have to success criteria
 	if doErr == nil && respErr == nil && !shouldRetry {
+		c.metrics.recordRequest(req, start, resp, nil)
+		finishSpan(reqSpan, resp, nil)
 		return resp, nil
 	}
 
@@ -117,38 +209,102 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	} else {
 		err = doErr
 	}
-
+	c.metrics.recordRequest(req, start, resp, err)
+	finishSpan(reqSpan, resp, err)
 	return resp, err
 }
 
+// admit checks the circuit breaker and the rate limiter and authenticates the request before an attempt, it returns
+// the time spent waiting for the rate limiter.
+func (c *Client) admit(req *http.Request) (time.Duration, error) {
+	if err := c.breaker.allow(req); err != nil {
+		return 0, err
+	}
+	waited, err := c.limiter.wait(req)
+	if waited > 0 {
+		c.log.Info(req.Context()).Str("key", c.limiter.config.Key(req)).Msgf("rate limited, waited %vms", waited.Milliseconds())
+	}
+	if err == nil {
+		err = c.authenticate(req)
+	}
+	if err != nil {
+		c.breaker.release(req)
+	}
+	return waited, err
+}
+
+// attempt sends an attempt, it reports whether the request was written.
+func (c *Client) attempt(ctx context.Context, req *http.Request, reqBody []byte, attempt int, wait, limited time.Duration) (*http.Response, bool, error) {
+	if req.ContentLength > 0 {
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	attemptReq, attemptSpan := c.startAttemptSpan(ctx, req, attempt, wait, limited)
+	attemptReq, written := traceWrite(attemptReq)
+	resp, doErr := c.send(attemptReq, reqBody)
+	finishSpan(attemptSpan, resp, doErr)
+	c.limiter.observe(req, resp)
+	c.metrics.recordAttempt(req, resp, doErr)
+	return resp, written.Load(), doErr
+}
+
+// send sends an attempt, hedging it when hedging is enabled and the method is idempotent.
+func (c *Client) send(req *http.Request, body []byte) (*http.Response, error) {
+	if c.hedger == nil || !isIdempotentMethod(req.Method) {
+		return c.Client.Do(req)
+	}
+	return c.hedger.do(c.Client, req, body)
+}
+
 // backOffAndRetry determines if the request should be retried and calculates the backoff duration.
-// It logs the retry attempt and waits for the backoff duration before retrying.
-func (c *Client) backOffAndRetry(i int, req *http.Request, resp *http.Response, doErr error) (bool, error) {
-	shouldRetry, respErr := c.checkRetry(req.Context(), resp, doErr)
+// Non-idempotent requests without an idempotency key are only retried when the request was not written.
+// The Retry-After header of 429 and 503 responses takes precedence over the backoff, capped by the maximum retry wait.
+// It logs the retry attempt and waits for the backoff duration before retrying, the duration waited is returned.
+func (c *Client) backOffAndRetry(i int, req *http.Request, resp *http.Response, doErr error, written bool) (bool, time.Duration, error) {
+	retryMax, checkRetry := c.policy(req.Context())
+	shouldRetry, respErr := checkRetry(req.Context(), resp, doErr)
+	c.breaker.record(req, shouldRetry)
 	if !shouldRetry || respErr != nil {
-		return shouldRetry, respErr
+		if !shouldRetry && respErr == nil && doErr == nil {
+			c.budget.deposit()
+		}
+		return shouldRetry, 0, respErr
+	}
+	if !c.safeToRetry(req, resp, doErr, written) {
+		c.log.Warn(req.Context()).Msgf("%v request without %v is not retried", req.Method, HeaderIdempotencyKey)
+		c.metrics.recordGiveUp(req)
+		return false, 0, nil
 	}
-	remain := c.retryMax - uint(i)
+	remain := retryMax - uint(i)
 	if remain <= 0 {
-		return false, respErr
+		c.metrics.recordGiveUp(req)
+		return false, 0, respErr
+	}
+	if !c.budget.withdraw() {
+		c.log.Warn(req.Context()).Msg("retry budget exhausted, not retrying")
+		c.metrics.recordGiveUp(req)
+		return false, 0, respErr
 	}
 	wait := c.backoff(c.minRetryWait, c.maxRetryWait, i, resp)
+	if retryAfter, ok := RetryAfter(resp); ok {
+		wait = min(retryAfter, c.maxRetryWait)
+	}
 	if resp != nil && resp.ContentLength > 0 {
 		defer resp.Body.Close()
 		resBlob, _ := io.ReadAll(resp.Body)
-		c.log.Warn(req.Context()).Str("response", string(resBlob)).Msgf("request failed with status code %v retry %v of %v in %vms, resp: ", resp.StatusCode, i+1, c.retryMax, wait.Milliseconds())
+		c.log.Warn(req.Context()).Str("response", string(resBlob)).Msgf("request failed with status code %v retry %v of %v in %vms, resp: ", resp.StatusCode, i+1, retryMax, wait.Milliseconds())
 	} else if doErr != nil {
-		c.log.Warn(req.Context()).Err(doErr).Msgf("request failed with error - retry %v of %v in %vms", i+1, c.retryMax, wait.Milliseconds())
+		c.log.Warn(req.Context()).Err(doErr).Msgf("request failed with error - retry %v of %v in %vms", i+1, retryMax, wait.Milliseconds())
 	} else {
-		c.log.Warn(req.Context()).Msgf("request failed - retry %v of %v in %vms", i+1, c.retryMax, wait.Milliseconds())
+		c.log.Warn(req.Context()).Msgf("request failed - retry %v of %v in %vms", i+1, retryMax, wait.Milliseconds())
 	}
 	timer := time.NewTimer(wait)
 	select {
 	case <-req.Context().Done():
 		timer.Stop()
+		c.metrics.recordGiveUp(req)
 		c.Client.CloseIdleConnections()
-		return false, req.Context().Err()
+		return false, wait, req.Context().Err()
 	case <-timer.C:
 	}
-	return true, nil
+	return true, wait, nil
 }
@@ -2,11 +2,26 @@ package retryhttp_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	e "errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/sabariramc/go-kit/errors"
+	span "github.com/sabariramc/go-kit/instrumentation"
+	"github.com/sabariramc/go-kit/instrumentation/memory"
+	"github.com/sabariramc/go-kit/log/correlation"
 	"github.com/sabariramc/go-kit/retryhttp"
 	"gotest.tools/v3/assert"
 )
@@ -49,3 +64,703 @@ func TestHttpRetry(t *testing.T) {
 	_, err = client.Get(ctx, "http://localhost:63001/panic")
 	assert.Error(t, err, "Get \"http://localhost:63001/panic\": EOF")
 }
+
+func TestHttpRetryMetrics(t *testing.T) {
+	srv := httptest.NewServer(getServer().Handler)
+	defer srv.Close()
+	ctx := context.Background()
+	meter := memory.NewMeter()
+	client := retryhttp.New(retryhttp.WithMeter(meter), func(c *retryhttp.Config) {
+		c.RetryMax = 2
+	})
+	res, err := client.Get(ctx, srv.URL+"/echo")
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	res, err = client.Get(ctx, srv.URL+"/error")
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusInternalServerError)
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts, span.Attr(span.HTTPStatusCode, "200")), float64(1))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts, span.Attr(span.HTTPStatusCode, "500")), float64(3))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientRetries), float64(2))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientGiveUps, span.Attr(span.ServerAddress, srv.Listener.Addr().String())), float64(1))
+	assert.Equal(t, meter.Count(retryhttp.MetricClientRequestDuration), 2)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	_, err = client.Get(timeoutCtx, slow.URL)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts, span.Attr(span.ErrorType, "timeout")), float64(1))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts, span.Attr(span.ErrorType, "transport")), float64(0))
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.Get(canceledCtx, slow.URL)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts, span.Attr(span.ErrorType, "canceled")), float64(1))
+}
+
+type spanKey struct{}
+
+type testSpan struct {
+	id         string
+	name       string
+	kind       string
+	parent     string
+	attributes map[string]any
+	statusCode int
+	err        error
+	finished   bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any)           { s.attributes[key] = value }
+func (s *testSpan) SetStatus(statusCode int, description string) { s.statusCode = statusCode }
+func (s *testSpan) SetError(err error, stackTrace string)        { s.err = err }
+func (s *testSpan) Finish()                                      { s.finished = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) NewSpanFromContext(ctx context.Context, operationName string, kind string, resourceName string) (context.Context, span.Span) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	sp := &testSpan{id: fmt.Sprintf("span-%v", len(t.spans)), name: operationName, kind: kind, parent: parent, attributes: map[string]any{}}
+	t.spans = append(t.spans, sp)
+	return context.WithValue(ctx, spanKey{}, sp.id), sp
+}
+
+func (t *testTracer) GetSpanFromContext(ctx context.Context) (span.Span, bool) {
+	return nil, false
+}
+
+func (t *testTracer) Inject(ctx context.Context, carrier span.Carrier) {
+	if id, ok := ctx.Value(spanKey{}).(string); ok {
+		carrier.Set("traceparent", id)
+	}
+}
+
+func (t *testTracer) Extract(ctx context.Context, carrier span.Carrier) context.Context {
+	return ctx
+}
+
+func TestHttpRetryTracing(t *testing.T) {
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("traceparent"))
+		if len(received) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	tr := &testTracer{}
+	client := retryhttp.New(retryhttp.WithTracer(tr))
+	res, err := client.Get(context.Background(), srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, len(tr.spans), 4)
+	parent := tr.spans[0]
+	assert.Equal(t, parent.kind, span.SpanKindClient)
+	assert.Equal(t, parent.attributes[retryhttp.AttrAttempts], 3)
+	assert.Equal(t, parent.statusCode, http.StatusOK)
+	assert.Assert(t, parent.finished)
+	for i, sp := range tr.spans[1:] {
+		assert.Equal(t, sp.kind, span.SpanKindClient)
+		assert.Equal(t, sp.parent, parent.id)
+		assert.Equal(t, sp.attributes[retryhttp.AttrAttempt], i+1)
+		assert.Equal(t, received[i], sp.id)
+		assert.Assert(t, sp.finished)
+	}
+	assert.Equal(t, tr.spans[1].attributes[span.HTTPStatusCode], http.StatusServiceUnavailable)
+	assert.Equal(t, tr.spans[2].attributes[retryhttp.AttrResendCount], 1)
+	_, ok := tr.spans[2].attributes[retryhttp.AttrBackoffInMs]
+	assert.Assert(t, ok)
+	assert.Equal(t, tr.spans[3].attributes[span.HTTPStatusCode], http.StatusOK)
+}
+
+func TestHttpCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	breaker := retryhttp.NewCircuitBreaker(retryhttp.WithConsecutiveFailures(3), retryhttp.WithCooldown(100*time.Millisecond))
+	client := retryhttp.New(retryhttp.WithCircuitBreaker(breaker))
+	host := srv.Listener.Addr().String()
+	_, err := client.Get(ctx, srv.URL)
+	var httpErr *errors.HTTPError
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.StatusCode, http.StatusServiceUnavailable)
+	assert.Equal(t, httpErr.Err.Code, retryhttp.ErrorCodeCircuitOpen)
+	assert.Equal(t, hits.Load(), int32(3))
+	assert.Equal(t, breaker.State(host), retryhttp.StateOpen)
+	assert.ErrorContains(t, breaker.HealthCheck(ctx), host)
+	_, err = client.Get(ctx, srv.URL)
+	assert.ErrorContains(t, err, retryhttp.ErrorCodeCircuitOpen)
+	assert.Equal(t, hits.Load(), int32(3))
+	healthy.Store(true)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, breaker.State(host), retryhttp.StateHalfOpen)
+	res, err := client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, breaker.State(host), retryhttp.StateClosed)
+	assert.NilError(t, breaker.HealthCheck(ctx))
+}
+
+func TestHttpRetryAfter(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	res.Header.Set("Retry-After", "3")
+	wait, ok := retryhttp.RetryAfter(res)
+	assert.Assert(t, ok)
+	assert.Equal(t, wait, 3*time.Second)
+	res.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	wait, ok = retryhttp.RetryAfter(res)
+	assert.Assert(t, ok)
+	assert.Assert(t, wait > 58*time.Second && wait <= time.Minute)
+	res.StatusCode = http.StatusInternalServerError
+	_, ok = retryhttp.RetryAfter(res)
+	assert.Assert(t, !ok)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	client := retryhttp.New(func(c *retryhttp.Config) {
+		c.RetryMax = 2
+		c.MaxRetryWait = 50 * time.Millisecond
+	})
+	start := time.Now()
+	res, err := client.Get(context.Background(), srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusServiceUnavailable)
+	assert.Equal(t, hits.Load(), int32(3))
+	assert.Assert(t, time.Since(start) >= 100*time.Millisecond && time.Since(start) < time.Second)
+}
+
+func TestHttpRetryPolicyOverride(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	client := retryhttp.New()
+	ctx := retryhttp.GetContextWithRetryMax(context.Background(), 1)
+	_, err := client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, hits.Load(), int32(2))
+	hits.Store(0)
+	ctx = retryhttp.GetContextWithCheckRetry(context.Background(), func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		return false, nil
+	})
+	_, err = client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, hits.Load(), int32(1))
+}
+
+func TestHttpRetryBudget(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	budget := retryhttp.NewRetryBudget(50, 2)
+	client := retryhttp.New(retryhttp.WithRetryBudget(budget))
+	_, err := client.Get(ctx, srv.URL+"/error")
+	assert.NilError(t, err)
+	assert.Equal(t, hits.Load(), int32(3))
+	assert.Equal(t, budget.Available(), float64(0))
+	for range 2 {
+		_, err = client.Get(ctx, srv.URL+"/echo")
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, budget.Available(), float64(1))
+	hits.Store(0)
+	_, err = client.Get(ctx, srv.URL+"/error")
+	assert.NilError(t, err)
+	assert.Equal(t, hits.Load(), int32(2))
+}
+
+func TestHttpIdempotentRetry(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(retryhttp.HeaderIdempotencyKey))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	retryMax := func(c *retryhttp.Config) {
+		c.RetryMax = 2
+	}
+	res, err := retryhttp.New(retryMax).Post(ctx, srv.URL, "application/json", strings.NewReader("{}"))
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusInternalServerError)
+	assert.DeepEqual(t, keys, []string{""})
+	keys = nil
+	_, err = retryhttp.New(retryMax, retryhttp.WithIdempotencyKey(nil)).Post(ctx, srv.URL, "application/json", strings.NewReader("{}"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(keys), 3)
+	assert.Assert(t, keys[0] != "")
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+	keys = nil
+	_, err = retryhttp.New(retryMax, retryhttp.WithRetryNonIdempotent()).Post(ctx, srv.URL, "application/json", strings.NewReader("{}"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(keys), 3)
+	keys = nil
+	corrCtx := correlation.GetContextWithCorrelationParam(ctx, &correlation.EventCorrelation{CorrelationID: "event-1"})
+	client := retryhttp.New(retryMax, retryhttp.WithIdempotencyKey(retryhttp.CorrelationIdempotencyKey))
+	for _, body := range []string{`{"id":1}`, `{"id":2}`, `{"id":1}`} {
+		_, err = client.Post(corrCtx, srv.URL, "application/json", strings.NewReader(body))
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, len(keys), 9)
+	assert.Equal(t, keys[0], keys[2])
+	assert.Assert(t, keys[0] != keys[3])
+	assert.Equal(t, keys[0], keys[6])
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	meter := memory.NewMeter()
+	_, err = retryhttp.New(retryMax, retryhttp.WithMeter(meter)).Post(ctx, closed.URL, "application/json", strings.NewReader("{}"))
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientAttempts), float64(3))
+}
+
+type jsonUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestHttpJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			var user jsonUser
+			json.NewDecoder(r.Body).Decode(&user)
+			user.ID = "1"
+			json.NewEncoder(w).Encode(user)
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error": {"code":"USER_EXISTS","message":"user already exists","description":{"id":"1"}}}`))
+		case "/problem":
+			encoder := &errors.ProblemEncoder{TypeBaseURI: "https://example.com/problems/"}
+			statusCode, contentType, body := encoder.Encode(r.Context(), &errors.HTTPError{StatusCode: http.StatusConflict, Err: &errors.Error{Code: "USER_EXISTS", Message: "user already exists", Description: map[string]any{"id": "1"}}})
+			w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+			w.WriteHeader(statusCode)
+			w.Write(body)
+		case "/blank":
+			w.Header().Set("Content-Type", errors.ContentTypeProblemJSON)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"type":"about:blank","status":403,"detail":"not allowed"}`))
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", 200)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := retryhttp.New(retryhttp.WithMaxResponseSize(128))
+	user, err := retryhttp.DoJSON[jsonUser, jsonUser](ctx, client, http.MethodPost, srv.URL+"/users", jsonUser{Name: "test"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, user, jsonUser{ID: "1", Name: "test"})
+	_, err = retryhttp.DoJSON[jsonUser, jsonUser](ctx, client, http.MethodPost, srv.URL+"/conflict", jsonUser{Name: "test"})
+	var httpErr *errors.HTTPError
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.StatusCode, http.StatusConflict)
+	assert.Equal(t, httpErr.Err.Code, "USER_EXISTS")
+	assert.Equal(t, httpErr.Err.Message, "user already exists")
+	assert.DeepEqual(t, httpErr.Err.Description, map[string]any{"id": "1"})
+	_, err = retryhttp.GetJSON[jsonUser](ctx, client, srv.URL+"/problem")
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.StatusCode, http.StatusConflict)
+	assert.Equal(t, httpErr.Err.Code, "USER_EXISTS")
+	assert.Equal(t, httpErr.Err.Message, "user already exists")
+	assert.DeepEqual(t, httpErr.Err.Description, map[string]any{"id": "1"})
+	_, err = retryhttp.GetJSON[jsonUser](ctx, client, srv.URL+"/blank")
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.Err.Code, "HTTP_403")
+	assert.Equal(t, httpErr.Err.Message, http.StatusText(http.StatusForbidden))
+	assert.DeepEqual(t, httpErr.Err.Description, map[string]any{"detail": "not allowed"})
+	_, err = retryhttp.GetJSON[jsonUser](ctx, client, srv.URL+"/missing")
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.StatusCode, http.StatusNotFound)
+	assert.Equal(t, httpErr.Err.Code, "HTTP_404")
+	assert.Equal(t, httpErr.Err.Description, "not found")
+	_, err = retryhttp.GetJSON[string](ctx, client, srv.URL+"/large")
+	assert.Assert(t, e.Is(err, retryhttp.ErrResponseTooLarge))
+}
+
+func TestHttpHedging(t *testing.T) {
+	var hits atomic.Int32
+	cancelled := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := retryhttp.New(retryhttp.WithHedging(retryhttp.NewHedger(retryhttp.WithHedgeDelay(20 * time.Millisecond))))
+	start := time.Now()
+	res, err := client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	body, err := io.ReadAll(res.Body)
+	assert.NilError(t, err)
+	res.Body.Close()
+	assert.Equal(t, string(body), "write")
+	assert.Assert(t, time.Since(start) < 500*time.Millisecond)
+	assert.Equal(t, hits.Load(), int32(2))
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("slow request was not cancelled")
+	}
+
+	hits.Store(1)
+	hedger := retryhttp.NewHedger(retryhttp.WithHedgePercentile(0.5, 10, 2))
+	client = retryhttp.New(retryhttp.WithHedging(hedger))
+	for range 2 {
+		res, err = client.Post(ctx, srv.URL, "application/json", strings.NewReader("{}"))
+		assert.NilError(t, err)
+		res, err = client.Get(ctx, srv.URL)
+		assert.NilError(t, err)
+		res.Body.Close()
+	}
+	assert.Assert(t, hedger.Delay(srv.Listener.Addr().String()) < 100*time.Millisecond)
+
+	hits.Store(0)
+	client = retryhttp.New(retryhttp.WithHedging(retryhttp.NewHedger(retryhttp.WithHedgeDelay(20*time.Millisecond), retryhttp.WithHedgeBudget(retryhttp.NewRetryBudget(0, 0)))), func(c *retryhttp.Config) {
+		c.Client.Timeout = 100 * time.Millisecond
+		c.RetryMax = 0
+	})
+	_, err = client.Get(ctx, srv.URL)
+	assert.ErrorContains(t, err, "Client.Timeout")
+	assert.Equal(t, hits.Load(), int32(1))
+}
+
+func TestHttpCache(t *testing.T) {
+	var hits sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count, _ := hits.LoadOrStore(r.URL.Path, &atomic.Int32{})
+		count.(*atomic.Int32).Add(1)
+		switch r.URL.Path {
+		case "/fresh", "/unread":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/swr":
+			w.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/large", "/chunked":
+			w.Header().Set("Cache-Control", "max-age=60")
+			if r.URL.Path == "/chunked" {
+				w.(http.Flusher).Flush()
+			}
+			w.Write([]byte(strings.Repeat("large", 10)))
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	hitCount := func(path string) int32 {
+		count, ok := hits.Load(path)
+		if !ok {
+			return 0
+		}
+		return count.(*atomic.Int32).Load()
+	}
+	ctx := context.Background()
+	meter := memory.NewMeter()
+	cache := retryhttp.NewMemoryCache(1 << 10)
+	client := retryhttp.New(retryhttp.WithCache(cache), retryhttp.WithMeter(meter))
+	get := func(path string) {
+		res, err := client.Get(ctx, srv.URL+path)
+		assert.NilError(t, err)
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		res.Body.Close()
+		assert.Equal(t, string(body), "write")
+	}
+	for _, path := range []string{"/fresh", "/etag", "/swr", "/nostore"} {
+		get(path)
+		get(path)
+	}
+	assert.Equal(t, hitCount("/fresh"), int32(1))
+	assert.Equal(t, hitCount("/etag"), int32(2))
+	assert.Equal(t, hitCount("/nostore"), int32(2))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientCache, span.Attr(retryhttp.AttrCacheResult, retryhttp.CacheHit)), float64(1))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientCache, span.Attr(retryhttp.AttrCacheResult, retryhttp.CacheRevalidated)), float64(1))
+	assert.Equal(t, meter.Sum(retryhttp.MetricClientCache, span.Attr(retryhttp.AttrCacheResult, retryhttp.CacheStale)), float64(1))
+	assert.Equal(t, cache.Len(), 3)
+	assert.Assert(t, poll(func() bool { return hitCount("/swr") == 2 }))
+	client = retryhttp.New(retryhttp.WithCache(cache), retryhttp.WithMaxResponseSize(32))
+	for _, path := range []string{"/large", "/large", "/chunked", "/chunked", "/fresh"} {
+		res, err := client.Get(ctx, srv.URL+path)
+		assert.NilError(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		res.Body.Close()
+		assert.Equal(t, len(body) > 0, true)
+	}
+	assert.Equal(t, hitCount("/large"), int32(2))
+	assert.Equal(t, hitCount("/chunked"), int32(2))
+	assert.Equal(t, hitCount("/fresh"), int32(1))
+	res, err := client.Get(ctx, srv.URL+"/unread")
+	assert.NilError(t, err)
+	res.Body.Close()
+	assert.Equal(t, cache.Len(), 3)
+}
+
+func TestHttpCacheCredentials(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := retryhttp.New(retryhttp.WithCache(retryhttp.NewMemoryCache(1 << 10)))
+	get := func(path, authorization string) string {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		assert.NilError(t, err)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		res, err := client.Do(req)
+		assert.NilError(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		res.Body.Close()
+		return string(body)
+	}
+	assert.Equal(t, get("/account", "Bearer alice"), "Bearer alice")
+	assert.Equal(t, get("/account", "Bearer bob"), "Bearer bob")
+	assert.Equal(t, hits.Load(), int32(2))
+	get("/private", "")
+	get("/private", "")
+	assert.Equal(t, hits.Load(), int32(4))
+	assert.Equal(t, get("/public", "Bearer alice"), "Bearer alice")
+	assert.Equal(t, get("/public", "Bearer bob"), "Bearer alice")
+	assert.Equal(t, hits.Load(), int32(5))
+	get("/shared", "")
+	assert.Equal(t, get("/shared", "Bearer alice"), "Bearer alice")
+	assert.Equal(t, hits.Load(), int32(7))
+	authClient := retryhttp.New(retryhttp.WithCache(retryhttp.NewMemoryCache(1<<10)), retryhttp.WithAuth(retryhttp.NewTokenAuth(retryhttp.TokenSourceFunc(func(ctx context.Context) (*retryhttp.Token, error) {
+		return &retryhttp.Token{AccessToken: "carol"}, nil
+	}))))
+	for range 2 {
+		res, err := authClient.Get(ctx, srv.URL+"/account")
+		assert.NilError(t, err)
+		res.Body.Close()
+	}
+	assert.Equal(t, hits.Load(), int32(9))
+}
+
+func poll(cond func() bool) bool {
+	for range 100 {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestHttpRateLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("write"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	host := srv.Listener.Addr().String()
+	limiter := retryhttp.NewRateLimiter(20)
+	client := retryhttp.New(retryhttp.WithRateLimiter(limiter))
+	start := time.Now()
+	for range 3 {
+		_, err := client.Get(ctx, srv.URL)
+		assert.NilError(t, err)
+	}
+	assert.Assert(t, time.Since(start) >= 90*time.Millisecond)
+	deadline, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := client.Get(deadline, srv.URL)
+	assert.ErrorContains(t, err, retryhttp.ErrorCodeRateLimited)
+
+	client = retryhttp.New(retryhttp.WithRateLimiter(retryhttp.NewRateLimiter(1, retryhttp.WithFailFast())))
+	_, err = client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	_, err = client.Get(ctx, srv.URL)
+	var httpErr *errors.HTTPError
+	assert.Assert(t, e.As(err, &httpErr))
+	assert.Equal(t, httpErr.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, httpErr.Err.Code, retryhttp.ErrorCodeRateLimited)
+
+	limiter = retryhttp.NewRateLimiter(100, retryhttp.WithBurst(10))
+	client = retryhttp.New(retryhttp.WithRateLimiter(limiter), func(c *retryhttp.Config) {
+		c.RetryMax = 0
+	})
+	res, err := client.Get(ctx, srv.URL+"/throttled")
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, limiter.Rate(host), float64(50))
+	_, err = client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, limiter.Rate(host), float64(51))
+}
+
+func TestHttpSigV4(t *testing.T) {
+	signer := &retryhttp.SigV4Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+		Now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	assert.NilError(t, err)
+	assert.NilError(t, signer.Authenticate(req))
+	assert.Equal(t, req.Header.Get("X-Amz-Date"), "20150830T123600Z")
+	assert.Equal(t, req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31")
+}
+
+func TestHttpAuth(t *testing.T) {
+	var tokens atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprintf(w, `{"access_token":"token-%v","token_type":"bearer","expires_in":3600}`, tokens.Add(1))
+	}))
+	defer tokenSrv.Close()
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(retryhttp.HeaderSignature)
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	source := retryhttp.NewCachedTokenSource(&retryhttp.ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "client", ClientSecret: "secret"}, time.Minute)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := source.Token(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, token.AccessToken, "token-1")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, tokens.Load(), int32(1))
+	client := retryhttp.New(retryhttp.WithAuth(retryhttp.NewTokenAuth(source)))
+	res, err := client.Get(ctx, srv.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, string(body), "Bearer token-2")
+	assert.Equal(t, tokens.Load(), int32(2))
+
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	source = retryhttp.NewCachedTokenSource(retryhttp.TokenSourceFunc(func(ctx context.Context) (*retryhttp.Token, error) {
+		switch fetches.Add(1) {
+		case 1:
+			return &retryhttp.Token{AccessToken: "expiring", Expiry: time.Now().Add(time.Second)}, nil
+		case 2:
+			<-release
+			return &retryhttp.Token{AccessToken: "rejected", Expiry: time.Now().Add(time.Hour)}, nil
+		default:
+			return &retryhttp.Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+		}
+	}), time.Minute)
+	for range 2 {
+		token, err := source.Token(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, token.AccessToken, "expiring")
+	}
+	assert.Assert(t, poll(func() bool { return fetches.Load() == 2 }))
+	source.Invalidate()
+	token, err := source.Token(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, token.AccessToken, "fresh")
+	close(release)
+	assert.Assert(t, poll(func() bool { return fetches.Load() == 3 }))
+	time.Sleep(10 * time.Millisecond)
+	token, err = source.Token(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, token.AccessToken, "fresh")
+
+	signer := &retryhttp.HMACSigner{KeyID: "key", Secret: []byte("secret"), Now: func() time.Time { return time.Unix(1700000000, 0) }}
+	client = retryhttp.New(retryhttp.WithAuth(signer))
+	_, err = client.Post(ctx, srv.URL+"/orders?id=1", "application/json", strings.NewReader(`{"id":1}`))
+	assert.NilError(t, err)
+	bodyHash := sha256.Sum256([]byte(`{"id":1}`))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("POST\n/orders?id=1\n1700000000\n" + hex.EncodeToString(bodyHash[:])))
+	assert.Equal(t, signature, hex.EncodeToString(mac.Sum(nil)))
+
+	signature = "unset"
+	client = retryhttp.New(retryhttp.WithHooks(retryhttp.ErrorHookFunc(func(req *http.Request) error {
+		return e.New("signing failed")
+	})))
+	_, err = client.Get(ctx, srv.URL)
+	assert.ErrorContains(t, err, "signing failed")
+	assert.Equal(t, signature, "unset")
+}
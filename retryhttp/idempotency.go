@@ -0,0 +1,98 @@
+package retryhttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/sabariramc/go-kit/log/correlation"
+)
+
+// HeaderIdempotencyKey marks a request as safe to retry regardless of its method.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// IdempotencyKeyFunc returns the idempotency key for a request.
+type IdempotencyKeyFunc func(req *http.Request) string
+
+// RandomIdempotencyKey returns a random key, the key stays stable across the attempts of a call.
+func RandomIdempotencyKey(req *http.Request) string {
+	return uuid.NewString()
+}
+
+// CorrelationIdempotencyKey derives the key from the correlation ID of the request context, the method, the URL and
+// the body, so replaying the same event yields the same key. Calls with identical method, URL and body made while
+// handling one event share the key and are deduplicated by the server, such calls need a KeyFunc of their own.
+// Requests without a correlation ID, or whose body cannot be read again, get a random key.
+func CorrelationIdempotencyKey(req *http.Request) string {
+	corr, ok := correlation.ExtractCorrelationParam(req.Context())
+	if !ok || corr.CorrelationID == "" {
+		return RandomIdempotencyKey(req)
+	}
+	body, err := requestBody(req)
+	if err != nil || body == nil && req.Body != nil && req.Body != http.NoBody {
+		return RandomIdempotencyKey(req)
+	}
+	h := sha256.New()
+	h.Write([]byte(corr.CorrelationID))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.String()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IdempotencyHook sets the HeaderIdempotencyKey header on non-idempotent requests that do not carry one.
+// Hooks run once per call, so every attempt sends the same key.
+type IdempotencyHook struct {
+	KeyFunc IdempotencyKeyFunc
+}
+
+func (h IdempotencyHook) Run(req *http.Request) {
+	if isIdempotent(req) {
+		return
+	}
+	keyFunc := h.KeyFunc
+	if keyFunc == nil {
+		keyFunc = RandomIdempotencyKey
+	}
+	req.Header.Set(HeaderIdempotencyKey, keyFunc(req))
+}
+
+// isIdempotent reports whether the method of the request is idempotent or the request carries an idempotency key.
+func isIdempotent(req *http.Request) bool {
+	return isIdempotentMethod(req.Method) || req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+// isIdempotentMethod reports whether the method is idempotent.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// traceWrite returns the request with a client trace reporting whether the request headers reached the connection.
+func traceWrite(req *http.Request) (*http.Request, *atomic.Bool) {
+	written := &atomic.Bool{}
+	trace := &httptrace.ClientTrace{
+		WroteHeaders: func() {
+			written.Store(true)
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), written
+}
+
+// safeToRetry reports whether a failed attempt can be sent again. Non-idempotent requests are only retried when the
+// attempt failed with a connection error before the request was written.
+func (c *Client) safeToRetry(req *http.Request, resp *http.Response, doErr error, written bool) bool {
+	if c.retryNonIdempotent || isIdempotent(req) {
+		return true
+	}
+	return resp == nil && doErr != nil && !written
+}
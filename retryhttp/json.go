@@ -0,0 +1,138 @@
+package retryhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	e "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sabariramc/go-kit/errors"
+)
+
+// DefaultMaxResponseSize is the default limit of response bodies read by the JSON helpers.
+const DefaultMaxResponseSize = 10 << 20
+
+// ErrResponseTooLarge is returned by the JSON helpers when a response body exceeds the maximum response size.
+var ErrResponseTooLarge = e.New("response too large")
+
+// DoJSON sends body encoded as JSON and decodes a 2xx response into Resp. Other responses are returned as
+// *errors.HTTPError, parsed from the error envelope of go-kit services when the body carries one.
+func DoJSON[Req, Resp any](ctx context.Context, client *Client, method, url string, body Req) (Resp, error) {
+	var res Resp
+	blob, err := json.Marshal(body)
+	if err != nil {
+		return res, fmt.Errorf("retryhttp.DoJSON: error marshalling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(blob))
+	if err != nil {
+		return res, fmt.Errorf("retryhttp.DoJSON: error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return sendJSON[Resp](client, req)
+}
+
+// GetJSON sends a GET request and decodes the response like DoJSON.
+func GetJSON[Resp any](ctx context.Context, client *Client, url string) (Resp, error) {
+	var res Resp
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return res, fmt.Errorf("retryhttp.GetJSON: error creating request: %w", err)
+	}
+	return sendJSON[Resp](client, req)
+}
+
+// sendJSON sends the request and decodes the response.
+func sendJSON[Resp any](client *Client, req *http.Request) (Resp, error) {
+	var res Resp
+	req.Header.Set("Accept", errors.ContentTypeJSON+", "+errors.ContentTypeProblemJSON)
+	resp, err := client.Do(req)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return res, fmt.Errorf("retryhttp.DoJSON: %w", err)
+	}
+	defer resp.Body.Close()
+	blob, err := io.ReadAll(io.LimitReader(resp.Body, client.maxResponseSize+1))
+	if err != nil {
+		return res, fmt.Errorf("retryhttp.DoJSON: error reading response: %w", err)
+	}
+	if int64(len(blob)) > client.maxResponseSize {
+		return res, fmt.Errorf("retryhttp.DoJSON: %w: exceeds %v bytes", ErrResponseTooLarge, client.maxResponseSize)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return res, ParseError(resp.StatusCode, resp.Header.Get("Content-Type"), blob)
+	}
+	if len(blob) == 0 {
+		return res, nil
+	}
+	if err := json.Unmarshal(blob, &res); err != nil {
+		return res, fmt.Errorf("retryhttp.DoJSON: error decoding response: %w", err)
+	}
+	return res, nil
+}
+
+// ParseError converts an error response into *errors.HTTPError. Bodies in the {"error": {...}} envelope written by
+// errors.EnvelopeEncoder keep their code, message and description. RFC 9457 problem details, told apart by the
+// application/problem+json content type, take the code from the last segment of the type, the message from the title
+// and the description from the other members. Other bodies become the description of an error coded
+// HTTP_<status code>.
+func ParseError(statusCode int, contentType string, body []byte) *errors.HTTPError {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == errors.ContentTypeProblemJSON {
+		if httpErr := parseProblem(statusCode, body); httpErr != nil {
+			return httpErr
+		}
+	}
+	var envelope struct {
+		Error *errors.Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Code != "" {
+		return &errors.HTTPError{StatusCode: statusCode, Err: envelope.Error}
+	}
+	httpErr := newStatusError(statusCode)
+	if len(body) > 0 {
+		httpErr.Err.Description = string(body)
+	}
+	return httpErr
+}
+
+// parseProblem converts a problem details body, it returns nil when the body is not a JSON object.
+func parseProblem(statusCode int, body []byte) *errors.HTTPError {
+	var members map[string]any
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil
+	}
+	httpErr := newStatusError(statusCode)
+	if problemType, _ := members["type"].(string); problemType != "" && problemType != "about:blank" {
+		httpErr.Err.Code = problemType[strings.LastIndex(problemType, "/")+1:]
+	}
+	if title, _ := members["title"].(string); title != "" {
+		httpErr.Err.Message = title
+	}
+	delete(members, "type")
+	delete(members, "title")
+	delete(members, "status")
+	if description, ok := members["description"]; ok && len(members) == 1 {
+		httpErr.Err.Description = description
+	} else if len(members) > 0 {
+		httpErr.Err.Description = members
+	}
+	return httpErr
+}
+
+// newStatusError returns an error coded HTTP_<status code>.
+func newStatusError(statusCode int) *errors.HTTPError {
+	return &errors.HTTPError{
+		StatusCode: statusCode,
+		Err: &errors.Error{
+			Code:    "HTTP_" + strconv.Itoa(statusCode),
+			Message: http.StatusText(statusCode),
+		},
+	}
+}
@@ -0,0 +1,183 @@
+package retryhttp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sabariramc/go-kit/errors"
+)
+
+// ErrorCodeRateLimited is the error code of the error returned when a request is rejected by the rate limiter.
+const ErrorCodeRateLimited = "RATE_LIMITED"
+
+// LimiterConfig contains the configuration of a RateLimiter.
+type LimiterConfig struct {
+	Rate     float64 // Rate is the number of requests per second allowed per key.
+	Burst    uint    // Burst is the number of requests that can be sent at once.
+	MinRate  float64 // MinRate is the lowest rate the limiter backs off to on 429 responses.
+	Decrease float64 // Decrease multiplies the rate of a key on a 429 response.
+	Increase float64 // Increase is added to the rate of a key on every other response, up to Rate.
+	FailFast bool    // FailFast rejects requests instead of waiting for a token.
+	Key      KeyFunc // Key returns the rate limit of a request.
+}
+
+// GetDefaultLimiterConfig returns a LimiterConfig with default settings for rate requests per second.
+func GetDefaultLimiterConfig(rate float64) LimiterConfig {
+	return LimiterConfig{
+		Rate:     rate,
+		Burst:    1,
+		MinRate:  rate / 10,
+		Decrease: 0.5,
+		Increase: rate / 100,
+		Key:      HostKey,
+	}
+}
+
+// LimiterOption represents an option function for configuring the limiter config.
+type LimiterOption func(*LimiterConfig)
+
+// WithBurst sets the number of requests that can be sent at once.
+func WithBurst(burst uint) LimiterOption {
+	return func(cfg *LimiterConfig) {
+		cfg.Burst = burst
+	}
+}
+
+// WithAIMD sets how the rate adapts to 429 responses, it is multiplied by decrease down to minRate on a 429 and
+// increased by increase on every other response.
+func WithAIMD(minRate, decrease, increase float64) LimiterOption {
+	return func(cfg *LimiterConfig) {
+		cfg.MinRate = minRate
+		cfg.Decrease = decrease
+		cfg.Increase = increase
+	}
+}
+
+// WithFailFast rejects requests with a RATE_LIMITED error instead of waiting for a token.
+func WithFailFast() LimiterOption {
+	return func(cfg *LimiterConfig) {
+		cfg.FailFast = true
+	}
+}
+
+// WithLimiterKey sets the function mapping requests to rate limits, for example to limit per API key instead of per host.
+func WithLimiterKey(key KeyFunc) LimiterOption {
+	return func(cfg *LimiterConfig) {
+		cfg.Key = key
+	}
+}
+
+// RateLimiter is a token bucket per key that adapts its rate to 429 responses, additively increasing and
+// multiplicatively decreasing it.
+type RateLimiter struct {
+	config  LimiterConfig
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the token bucket of a key, tokens go negative for requests waiting on a reservation.
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing rate requests per second per key.
+func NewRateLimiter(rate float64, options ...LimiterOption) *RateLimiter {
+	config := GetDefaultLimiterConfig(rate)
+	for _, opt := range options {
+		opt(&config)
+	}
+	if config.Burst == 0 {
+		config.Burst = 1
+	}
+	return &RateLimiter{
+		config:  config,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Rate returns the current rate of key.
+func (l *RateLimiter) Rate(key string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[key]; ok {
+		return b.rate
+	}
+	return l.config.Rate
+}
+
+// bucket returns the refilled bucket of key, must be called with the lock held.
+func (l *RateLimiter) bucket(key string) *bucket {
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{rate: l.config.Rate, tokens: float64(l.config.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, float64(l.config.Burst))
+	b.last = now
+	return b
+}
+
+// wait takes a token for the request, waiting for it unless the limiter fails fast. It returns the time waited and a
+// RATE_LIMITED error when the token is not available in time.
+func (l *RateLimiter) wait(req *http.Request) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	ctx := req.Context()
+	key := l.config.Key(req)
+	l.mu.Lock()
+	b := l.bucket(key)
+	if b.tokens >= 1 {
+		b.tokens--
+		l.mu.Unlock()
+		return 0, nil
+	}
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); l.config.FailFast || (ok && time.Until(deadline) < delay) {
+		l.mu.Unlock()
+		return 0, newRateLimitedError(key)
+	}
+	b.tokens--
+	l.mu.Unlock()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.buckets[key].tokens++
+		l.mu.Unlock()
+		return delay, ctx.Err()
+	case <-timer.C:
+	}
+	return delay, nil
+}
+
+// observe adapts the rate of the key of the request to the response.
+func (l *RateLimiter) observe(req *http.Request, resp *http.Response) {
+	if l == nil || resp == nil {
+		return
+	}
+	key := l.config.Key(req)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.rate = max(b.rate*l.config.Decrease, l.config.MinRate)
+		return
+	}
+	b.rate = min(b.rate+l.config.Increase, l.config.Rate)
+}
+
+func newRateLimitedError(key string) *errors.HTTPError {
+	return &errors.HTTPError{
+		StatusCode: http.StatusTooManyRequests,
+		Err: &errors.Error{
+			Code:    ErrorCodeRateLimited,
+			Message: "rate limit exceeded for " + key,
+		},
+	}
+}
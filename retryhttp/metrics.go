@@ -0,0 +1,99 @@
+package retryhttp
+
+import (
+	"context"
+	e "errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Metric names recorded by the Client.
+const (
+	MetricClientAttempts        = "http.client.attempts"
+	MetricClientRetries         = "http.client.retries"
+	MetricClientGiveUps         = "http.client.giveups"
+	MetricClientRequestDuration = "http.client.request.duration"
+	MetricClientCache           = "http.client.cache.lookups"
+)
+
+// AttrCacheResult is the attribute holding the result of a cache lookup.
+const AttrCacheResult = "http.client.cache.result"
+
+// clientMetrics holds the instruments of a Client.
+type clientMetrics struct {
+	attempts span.Counter
+	retries  span.Counter
+	giveUps  span.Counter
+	duration span.Histogram
+	cache    span.Counter
+}
+
+func newClientMetrics(meter span.Meter) *clientMetrics {
+	if meter == nil {
+		meter = span.NoopMeter{}
+	}
+	return &clientMetrics{
+		attempts: meter.Counter(MetricClientAttempts, span.UnitCount, "Number of HTTP requests sent including retries"),
+		retries:  meter.Counter(MetricClientRetries, span.UnitCount, "Number of retried HTTP requests"),
+		giveUps:  meter.Counter(MetricClientGiveUps, span.UnitCount, "Number of HTTP requests that still warranted a retry when the client stopped retrying"),
+		duration: meter.Histogram(MetricClientRequestDuration, span.UnitSeconds, "Duration of HTTP requests including retries"),
+		cache:    meter.Counter(MetricClientCache, span.UnitCount, "Number of HTTP cache lookups by result"),
+	}
+}
+
+// requestAttrs returns the attributes identifying the request.
+func requestAttrs(req *http.Request) []span.Attribute {
+	return []span.Attribute{
+		span.Attr(span.HTTPRequestMethod, req.Method),
+		span.Attr(span.ServerAddress, req.URL.Host),
+	}
+}
+
+// outcomeAttrs returns the request attributes with the response status code or, when there is no response, the error type.
+func outcomeAttrs(req *http.Request, resp *http.Response, err error) []span.Attribute {
+	attrs := requestAttrs(req)
+	if resp != nil {
+		return append(attrs, span.Attr(span.HTTPStatusCode, strconv.Itoa(resp.StatusCode)))
+	}
+	if err != nil {
+		return append(attrs, span.Attr(span.ErrorType, errorType(err)))
+	}
+	return attrs
+}
+
+// errorType classifies a transport error, http.Client wraps the errors of the context in a *url.Error.
+func errorType(err error) string {
+	var netErr net.Error
+	switch {
+	case e.Is(err, context.Canceled):
+		return "canceled"
+	case e.Is(err, context.DeadlineExceeded), e.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "transport"
+	}
+}
+
+func (m *clientMetrics) recordAttempt(req *http.Request, resp *http.Response, err error) {
+	m.attempts.Add(req.Context(), 1, outcomeAttrs(req, resp, err)...)
+}
+
+func (m *clientMetrics) recordRetry(req *http.Request) {
+	m.retries.Add(req.Context(), 1, requestAttrs(req)...)
+}
+
+func (m *clientMetrics) recordGiveUp(req *http.Request) {
+	m.giveUps.Add(req.Context(), 1, requestAttrs(req)...)
+}
+
+func (m *clientMetrics) recordRequest(req *http.Request, start time.Time, resp *http.Response, err error) {
+	m.duration.Record(req.Context(), time.Since(start).Seconds(), outcomeAttrs(req, resp, err)...)
+}
+
+func (m *clientMetrics) recordCache(req *http.Request, result string) {
+	m.cache.Add(req.Context(), 1, append(requestAttrs(req), span.Attr(AttrCacheResult, result))...)
+}
@@ -0,0 +1,287 @@
+// Package mock provides scripted and recording http.RoundTrippers for testing code built on retryhttp.Client.
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	e "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrNoMatch is returned by Transport when no expectation matches a request.
+var ErrNoMatch = e.New("mock: no expectation matches the request")
+
+// Matcher reports whether a request matches, body is a copy of the request body.
+type Matcher func(req *http.Request, body []byte) bool
+
+// Method matches the request method.
+func Method(method string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.Method == method
+	}
+}
+
+// Path matches the URL path.
+func Path(path string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.URL.Path == path
+	}
+}
+
+// Query matches a query parameter.
+func Query(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.URL.Query().Get(key) == value
+	}
+}
+
+// Header matches a request header.
+func Header(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.Header.Get(key) == value
+	}
+}
+
+// JSONBody matches requests whose JSON body is equal to the JSON encoding of v, ignoring formatting and key order.
+func JSONBody(v any) Matcher {
+	blob, err := json.Marshal(v)
+	var want any
+	if err == nil {
+		err = json.Unmarshal(blob, &want)
+	}
+	return func(req *http.Request, body []byte) bool {
+		var got any
+		if err != nil || json.Unmarshal(body, &got) != nil {
+			return false
+		}
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// Response is a scripted response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Err        error         // Err is returned instead of a response, for example to simulate connection errors.
+	Delay      time.Duration // Delay is waited before responding, cut short when the request context is done.
+}
+
+// Status returns a response with an empty body.
+func Status(statusCode int) Response {
+	return Response{StatusCode: statusCode}
+}
+
+// Text returns a plain text response.
+func Text(statusCode int, body string) Response {
+	return Response{StatusCode: statusCode, Header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}}, Body: []byte(body)}
+}
+
+// JSON returns a response with the JSON encoding of v, it panics when v cannot be encoded.
+func JSON(statusCode int, v any) Response {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("mock.JSON: error marshalling body: %v", err))
+	}
+	return Response{StatusCode: statusCode, Header: http.Header{"Content-Type": {"application/json"}}, Body: blob}
+}
+
+// Error returns a response failing the round trip with err.
+func Error(err error) Response {
+	return Response{Err: err}
+}
+
+// Call is a request received by a Transport.
+type Call struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Expectation is a set of matchers and the responses sent to the matching requests.
+type Expectation struct {
+	matchers  []Matcher
+	responses []Response
+	times     int
+	calls     int
+}
+
+// Respond sets the responses sent in sequence to the matching requests, the last response repeats. Without
+// responses the expectation keeps answering 200.
+func (x *Expectation) Respond(responses ...Response) *Expectation {
+	if len(responses) == 0 {
+		return x
+	}
+	x.responses = responses
+	return x
+}
+
+// Times sets the number of calls expected by AssertExpectations, the expectation stops matching once it is reached.
+func (x *Expectation) Times(n int) *Expectation {
+	x.times = n
+	return x
+}
+
+// matches reports whether the request matches every matcher and the expectation can take more calls.
+func (x *Expectation) matches(req *http.Request, body []byte) bool {
+	if x.times > 0 && x.calls >= x.times {
+		return false
+	}
+	for _, m := range x.matchers {
+		if !m(req, body) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestingT is the subset of testing.TB used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Transport is an http.RoundTripper answering requests with the responses of the first matching expectation.
+type Transport struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// New creates a Transport without expectations.
+func New() *Transport {
+	return &Transport{}
+}
+
+// On adds an expectation matching requests that satisfy every matcher, it responds with 200 until Respond is called.
+func (t *Transport) On(matchers ...Matcher) *Expectation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	x := &Expectation{matchers: matchers, responses: []Response{Status(http.StatusOK)}}
+	t.expectations = append(t.expectations, x)
+	return x
+}
+
+// Client returns an http.Client sending requests through the transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.calls = append(t.calls, Call{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: body})
+	var res Response
+	found := false
+	for _, x := range t.expectations {
+		if x.matches(req, body) {
+			res = x.responses[min(x.calls, len(x.responses)-1)]
+			x.calls++
+			found = true
+			break
+		}
+	}
+	t.mu.Unlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %v %v", ErrNoMatch, req.Method, req.URL)
+	}
+	if res.Delay > 0 {
+		timer := time.NewTimer(res.Delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return newResponse(req, res.StatusCode, res.Header, res.Body), nil
+}
+
+// Calls returns the requests received by the transport.
+func (t *Transport) Calls() []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Call(nil), t.calls...)
+}
+
+// CallCount returns the number of requests received that satisfy every matcher.
+func (t *Transport) CallCount(matchers ...Matcher) int {
+	count := 0
+	for _, call := range t.Calls() {
+		req, err := http.NewRequest(call.Method, call.URL, nil)
+		if err != nil {
+			continue
+		}
+		req.Header = call.Header
+		matched := true
+		for _, m := range matchers {
+			if !m(req, call.Body) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertExpectations fails the test when an expectation with Times was not called as many times, or was never
+// called otherwise.
+func (t *Transport) AssertExpectations(tb TestingT) {
+	tb.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, x := range t.expectations {
+		if x.times > 0 && x.calls != x.times {
+			tb.Errorf("mock: expectation %v called %v times, expected %v", i, x.calls, x.times)
+		} else if x.calls == 0 {
+			tb.Errorf("mock: expectation %v not called", i)
+		}
+	}
+}
+
+// readBody returns a copy of the request body and restores the body for the caller.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("mock: error reading request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// newResponse builds a response to req.
+func newResponse(req *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,79 @@
+package mock_test
+
+import (
+	"context"
+	e "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sabariramc/go-kit/retryhttp"
+	"github.com/sabariramc/go-kit/retryhttp/mock"
+	"gotest.tools/v3/assert"
+)
+
+type order struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+func TestTransport(t *testing.T) {
+	m := mock.New()
+	m.On(mock.Method(http.MethodGet), mock.Path("/orders/1")).Respond(mock.Status(http.StatusServiceUnavailable), mock.Status(http.StatusServiceUnavailable), mock.JSON(http.StatusOK, order{ID: "1", Amount: 10}))
+	m.On(mock.Method(http.MethodPost), mock.Path("/orders"), mock.JSONBody(order{Amount: 20})).Respond(mock.JSON(http.StatusCreated, order{ID: "2", Amount: 20})).Times(1)
+	client := retryhttp.New(retryhttp.WithHTTPClient(m.Client()))
+	ctx := context.Background()
+	res, err := retryhttp.GetJSON[order](ctx, client, "http://orders.local/orders/1")
+	assert.NilError(t, err)
+	assert.Equal(t, res, order{ID: "1", Amount: 10})
+	res, err = retryhttp.DoJSON[map[string]any, order](ctx, client, http.MethodPost, "http://orders.local/orders", map[string]any{"amount": 20, "id": ""})
+	assert.NilError(t, err)
+	assert.Equal(t, res.ID, "2")
+	assert.Equal(t, m.CallCount(mock.Path("/orders/1")), 3)
+	assert.Equal(t, len(m.Calls()), 4)
+	m.AssertExpectations(t)
+	_, err = client.Get(ctx, "http://orders.local/missing")
+	assert.Assert(t, e.Is(err, mock.ErrNoMatch))
+	m.On(mock.Path("/health")).Respond()
+	health, err := client.Get(ctx, "http://orders.local/health")
+	assert.NilError(t, err)
+	assert.Equal(t, health.StatusCode, http.StatusOK)
+}
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	golden := filepath.Join(t.TempDir(), "testdata", "orders.json")
+	recorder, err := mock.NewRecorder(golden, mock.ModeRecord, nil)
+	assert.NilError(t, err)
+	client := retryhttp.New(retryhttp.WithHTTPClient(recorder.Client()))
+	ctx := context.Background()
+	send := func(client *retryhttp.Client) string {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/orders", strings.NewReader("order"))
+		assert.NilError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		res, err := client.Do(req)
+		assert.NilError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	assert.Equal(t, send(client), "POST /orders order")
+	assert.NilError(t, recorder.Save())
+	srv.Close()
+
+	recorder, err = mock.NewRecorder(golden, mock.ModeReplay, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(recorder.Exchanges()), 1)
+	assert.Equal(t, recorder.Exchanges()[0].Request.Header.Get("Authorization"), "")
+	client = retryhttp.New(retryhttp.WithHTTPClient(recorder.Client()), func(c *retryhttp.Config) {
+		c.RetryMax = 0
+	})
+	assert.Equal(t, send(client), "POST /orders order")
+	_, err = client.Get(ctx, srv.URL+"/orders")
+	assert.Assert(t, e.Is(err, mock.ErrNoMatch))
+}
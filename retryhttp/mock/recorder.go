@@ -0,0 +1,179 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EnvRecord switches recorders created with ModeFromEnv to recording when set to a non-empty value.
+const EnvRecord = "RETRYHTTP_RECORD"
+
+// sensitiveHeaders are removed from recorded exchanges so that credentials are not saved in golden files.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Amz-Security-Token"}
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	ModeReplay Mode = iota // ModeReplay answers requests from the golden file without network access.
+	ModeRecord             // ModeRecord sends requests to the real transport and saves the exchanges.
+)
+
+// ModeFromEnv returns ModeRecord when the EnvRecord environment variable is set and ModeReplay otherwise.
+func ModeFromEnv() Mode {
+	if os.Getenv(EnvRecord) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// RecordedRequest is the request of an Exchange.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an Exchange.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Exchange is a request and its response saved in a golden file.
+type Exchange struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Recorder is an http.RoundTripper recording real exchanges to a golden file and replaying them offline.
+// Replayed requests are matched on method, URL and body, each exchange answers a single request in recording order.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+// NewRecorder creates a Recorder for the golden file at path. In ModeReplay the file is loaded, in ModeRecord requests
+// are sent with transport, http.DefaultTransport when nil, and saved by Save.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, transport: transport}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("mock.NewRecorder: error reading golden file: %w", err)
+	}
+	if err := json.Unmarshal(blob, &r.exchanges); err != nil {
+		return nil, fmt.Errorf("mock.NewRecorder: error decoding golden file: %w", err)
+	}
+	r.used = make([]bool, len(r.exchanges))
+	return r, nil
+}
+
+// Client returns an http.Client sending requests through the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("mock: error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, Exchange{
+		Request:  RecordedRequest{Method: req.Method, URL: normalizeURL(req.URL.String()), Header: redact(req.Header), Body: string(body)},
+		Response: RecordedResponse{StatusCode: resp.StatusCode, Header: redact(resp.Header), Body: string(respBody)},
+	})
+	return resp, nil
+}
+
+// replay answers the request with the first unused matching exchange.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	url := normalizeURL(req.URL.String())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, x := range r.exchanges {
+		if r.used[i] || x.Request.Method != req.Method || x.Request.URL != url || x.Request.Body != string(body) {
+			continue
+		}
+		r.used[i] = true
+		return newResponse(req, x.Response.StatusCode, x.Response.Header, []byte(x.Response.Body)), nil
+	}
+	return nil, fmt.Errorf("%w: %v %v not recorded in %v", ErrNoMatch, req.Method, url, r.path)
+}
+
+// Exchanges returns the recorded or loaded exchanges.
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// Save writes the recorded exchanges to the golden file, it does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	blob, err := json.MarshalIndent(r.exchanges, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("mock.Recorder.Save: error encoding exchanges: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("mock.Recorder.Save: error creating directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, blob, 0o644); err != nil {
+		return fmt.Errorf("mock.Recorder.Save: error writing golden file: %w", err)
+	}
+	return nil
+}
+
+// redact returns a copy of the header without the sensitive headers.
+func redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range sensitiveHeaders {
+		header.Del(name)
+	}
+	return header
+}
+
+// normalizeURL strips the fragment of a URL.
+func normalizeURL(u string) string {
+	if i := strings.IndexByte(u, '#'); i >= 0 {
+		return u[:i]
+	}
+	return u
+}
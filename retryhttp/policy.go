@@ -0,0 +1,57 @@
+package retryhttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type retryMaxKey struct{}
+
+type checkRetryKey struct{}
+
+// GetContextWithRetryMax overrides the maximum number of retries of the client for requests made with the context.
+func GetContextWithRetryMax(ctx context.Context, retryMax uint) context.Context {
+	return context.WithValue(ctx, retryMaxKey{}, retryMax)
+}
+
+// GetContextWithCheckRetry overrides the retry policy of the client for requests made with the context.
+func GetContextWithCheckRetry(ctx context.Context, checkRetry CheckRetry) context.Context {
+	return context.WithValue(ctx, checkRetryKey{}, checkRetry)
+}
+
+// policy returns the maximum number of retries and the retry policy for a request context.
+func (c *Client) policy(ctx context.Context) (uint, CheckRetry) {
+	retryMax, checkRetry := c.retryMax, c.checkRetry
+	if v, ok := ctx.Value(retryMaxKey{}).(uint); ok {
+		retryMax = v
+	}
+	if v, ok := ctx.Value(checkRetryKey{}).(CheckRetry); ok && v != nil {
+		checkRetry = v
+	}
+	return retryMax, checkRetry
+}
+
+// RetryAfter returns the wait requested by the Retry-After header of a 429 or 503 response, the header can either be
+// a number of seconds or an HTTP date.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(at), 0), true
+}
@@ -0,0 +1,157 @@
+package retryhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set by HMACSigner.
+const (
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureKeyID     = "X-Signature-Key-ID"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+)
+
+// HMACSigner signs requests with HMAC-SHA256 over the method, the path and query, the timestamp and the SHA-256 of
+// the body, separated by new lines. The hex encoded signature is sent in the HeaderSignature header.
+type HMACSigner struct {
+	KeyID  string
+	Secret []byte
+	Now    func() time.Time // Now returns the signing time, time.Now when nil.
+}
+
+func (s *HMACSigner) Authenticate(req *http.Request) error {
+	body, err := requestBody(req)
+	if err != nil {
+		return fmt.Errorf("HMACSigner.Authenticate: error reading body: %w", err)
+	}
+	timestamp := strconv.FormatInt(signingTime(s.Now).Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n" + timestamp + "\n" + hex.EncodeToString(bodyHash[:])))
+	req.Header.Set(HeaderSignatureKeyID, s.KeyID)
+	req.Header.Set(HeaderSignatureTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// SigV4Signer signs requests with the AWS Signature Version 4 scheme.
+type SigV4Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // SessionToken is sent in the X-Amz-Security-Token header when set.
+	Region          string
+	Service         string
+	Now             func() time.Time // Now returns the signing time, time.Now when nil.
+}
+
+func (s *SigV4Signer) Authenticate(req *http.Request) error {
+	body, err := requestBody(req)
+	if err != nil {
+		return fmt.Errorf("SigV4Signer.Authenticate: error reading body: %w", err)
+	}
+	now := signingTime(s.Now).UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	headers, signedHeaders := canonicalHeaders(req)
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		headers,
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + s.Region + "/" + s.Service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+func signingTime(now func() time.Time) time.Time {
+	if now == nil {
+		return time.Now()
+	}
+	return now()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalPath returns the URI encoded path of the request.
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery returns the query parameters sorted by name and value and URI encoded.
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEscape(name)+"="+uriEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func uriEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// canonicalHeaders returns the canonical headers, signing the host, the content type and the X-Amz-* headers,
+// followed by the list of signed headers.
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, v := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(v))
+			for i := range v {
+				trimmed[i] = strings.Join(strings.Fields(v[i]), " ")
+			}
+			values[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sb := &strings.Builder{}
+	for _, name := range names {
+		sb.WriteString(name + ":" + values[name] + "\n")
+	}
+	return sb.String(), strings.Join(names, ";")
+}
@@ -0,0 +1,78 @@
+package retryhttp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	span "github.com/sabariramc/go-kit/instrumentation"
+)
+
+// Attribute keys set on client spans in addition to the instrumentation keys.
+const (
+	AttrURLFull     = "url.full"
+	AttrResendCount = "http.request.resend_count"
+	AttrAttempt     = "retry.attempt"
+	AttrAttempts    = "retry.attempts"
+	AttrBackoffInMs = "retry.backoff_ms"
+	AttrRateLimitMs = "ratelimit.wait_ms"
+)
+
+const (
+	spanNameRequest = "http.client.request"
+	spanNameAttempt = "http.client.attempt"
+)
+
+// startRequestSpan starts the span of a logical call, it returns a nil span when no tracer is configured.
+func (c *Client) startRequestSpan(req *http.Request) (context.Context, span.Span) {
+	if c.tr == nil {
+		return req.Context(), nil
+	}
+	ctx, sp := c.tr.NewSpanFromContext(req.Context(), spanNameRequest, span.SpanKindClient, req.Method+" "+req.URL.Host)
+	sp.SetAttribute(span.HTTPRequestMethod, req.Method)
+	sp.SetAttribute(span.ServerAddress, req.URL.Host)
+	sp.SetAttribute(AttrURLFull, req.URL.Redacted())
+	return ctx, sp
+}
+
+// startAttemptSpan starts the span of an attempt as a child of the call span and injects its trace context into the
+// request headers. It returns the request to send, bound to the attempt span context.
+func (c *Client) startAttemptSpan(ctx context.Context, req *http.Request, attempt int, wait, limited time.Duration) (*http.Request, span.Span) {
+	if c.tr == nil {
+		return req, nil
+	}
+	ctx, sp := c.tr.NewSpanFromContext(ctx, spanNameAttempt, span.SpanKindClient, req.Method+" "+req.URL.Host)
+	sp.SetAttribute(span.HTTPRequestMethod, req.Method)
+	sp.SetAttribute(span.ServerAddress, req.URL.Host)
+	sp.SetAttribute(AttrAttempt, attempt)
+	if attempt > 1 {
+		sp.SetAttribute(AttrResendCount, attempt-1)
+		sp.SetAttribute(AttrBackoffInMs, wait.Milliseconds())
+	}
+	if limited > 0 {
+		sp.SetAttribute(AttrRateLimitMs, limited.Milliseconds())
+	}
+	if c.propagator != nil {
+		c.propagator.Inject(ctx, span.HeaderCarrier(req.Header))
+	}
+	return req.WithContext(ctx), sp
+}
+
+// finishSpan records the response status or error on the span and finishes it, a nil span is ignored.
+func finishSpan(sp span.Span, resp *http.Response, err error) {
+	if sp == nil {
+		return
+	}
+	if resp != nil {
+		sp.SetAttribute(span.HTTPStatusCode, resp.StatusCode)
+		sp.SetStatus(resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err != nil {
+		sp.SetAttribute(span.ErrorType, errorType(err))
+		sp.SetError(err, "")
+		if resp == nil {
+			sp.SetStatus(http.StatusInternalServerError, err.Error())
+		}
+	}
+	sp.Finish()
+}
@@ -0,0 +1,12 @@
+module github.com/sabariramc/go-kit/validate
+
+go 1.24.4
+
+require (
+	github.com/sabariramc/go-kit/errors v1.0.2
+	gotest.tools/v3 v3.5.2
+)
+
+require github.com/google/go-cmp v0.5.9 // indirect
+
+replace github.com/sabariramc/go-kit/errors => ../errors
@@ -0,0 +1,148 @@
+package validate
+
+import (
+	e "errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var builtinRules = map[string]RuleFunc{
+	"min":   Min,
+	"max":   Max,
+	"len":   Len,
+	"regex": Regex,
+	"enum":  Enum,
+	"email": Email,
+	"uuid":  UUID,
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// patterns caches the compiled patterns of the regex rule.
+var patterns sync.Map
+
+// Min checks that numbers are at least param, and that strings, slices and maps have at least param elements.
+func Min(v reflect.Value, param string) error {
+	return compare(v, param, "min", func(value, limit float64) bool { return value >= limit }, "at least")
+}
+
+// Max checks that numbers are at most param, and that strings, slices and maps have at most param elements.
+func Max(v reflect.Value, param string) error {
+	return compare(v, param, "max", func(value, limit float64) bool { return value <= limit }, "at most")
+}
+
+// Len checks that strings, slices and maps have exactly param elements.
+func Len(v reflect.Value, param string) error {
+	limit, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("invalid len parameter %v", param)
+	}
+	n, ok := length(v)
+	if !ok {
+		return fmt.Errorf("len is not supported for %v", v.Kind())
+	}
+	if n != limit {
+		return fmt.Errorf("length must be %v", limit)
+	}
+	return nil
+}
+
+// Regex checks that strings match the pattern in param.
+func Regex(v reflect.Value, param string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("regex is not supported for %v", v.Kind())
+	}
+	re, ok := patterns.Load(param)
+	if !ok {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("invalid regex parameter %v", param)
+		}
+		re, _ = patterns.LoadOrStore(param, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(v.String()) {
+		return fmt.Errorf("must match %v", param)
+	}
+	return nil
+}
+
+// Enum checks that the value is one of the values in param, separated by |.
+func Enum(v reflect.Value, param string) error {
+	value := fmt.Sprint(v.Interface())
+	values := strings.Split(param, "|")
+	for _, allowed := range values {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %v", strings.Join(values, ", "))
+}
+
+// Email checks that strings are email addresses.
+func Email(v reflect.Value, param string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("email is not supported for %v", v.Kind())
+	}
+	addr, err := mail.ParseAddress(v.String())
+	if err != nil || addr.Address != v.String() {
+		return e.New("must be a valid email address")
+	}
+	return nil
+}
+
+// UUID checks that strings are UUIDs in the canonical form.
+func UUID(v reflect.Value, param string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("uuid is not supported for %v", v.Kind())
+	}
+	if !uuidPattern.MatchString(v.String()) {
+		return e.New("must be a valid UUID")
+	}
+	return nil
+}
+
+func compare(v reflect.Value, param, name string, ok func(value, limit float64) bool, bound string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %v parameter %v", name, param)
+	}
+	var value float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	default:
+		n, isLen := length(v)
+		if !isLen {
+			return fmt.Errorf("%v is not supported for %v", name, v.Kind())
+		}
+		if !ok(float64(n), limit) {
+			return fmt.Errorf("length must be %v %v", bound, param)
+		}
+		return nil
+	}
+	if !ok(value, limit) {
+		return fmt.Errorf("must be %v %v", bound, param)
+	}
+	return nil
+}
+
+// length returns the number of characters of strings and the number of elements of slices, arrays and maps.
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,327 @@
+// Package validate validates structs with rules declared in struct tags, such as `validate:"required,max=64"`.
+// Rules are checked on zero values unless the tag has omitempty, nil pointers are only checked by required.
+package validate
+
+import (
+	e "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sabariramc/go-kit/errors"
+)
+
+// ErrorCodeValidation is the error code of the error returned when a value violates its rules.
+const ErrorCodeValidation = "VALIDATION_FAILED"
+
+// DefaultTag is the struct tag holding the rules of a field.
+const DefaultTag = "validate"
+
+// Violation is a rule a field does not satisfy, Field is the path of the field using its JSON names, such as
+// items[0].name.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// RuleFunc checks a value against the parameter of a rule, the message of the returned error is the message of the
+// violation. Pointers are dereferenced before the rule is called.
+type RuleFunc func(v reflect.Value, param string) error
+
+// Config contains the configuration of a Validator.
+type Config struct {
+	Tag      string              // Tag is the struct tag holding the rules of a field.
+	NameTags []string            // NameTags are the struct tags naming fields in violations, the first one set is used.
+	Rules    map[string]RuleFunc // Rules are the rules available in tags by name.
+}
+
+// GetDefaultConfig returns a Config with the built-in rules, naming fields by their json tag.
+func GetDefaultConfig() Config {
+	rules := make(map[string]RuleFunc, len(builtinRules))
+	for name, fn := range builtinRules {
+		rules[name] = fn
+	}
+	return Config{
+		Tag:      DefaultTag,
+		NameTags: []string{"json"},
+		Rules:    rules,
+	}
+}
+
+// Option represents an option function for configuring the validator config.
+type Option func(*Config)
+
+// WithTag sets the struct tag holding the rules of a field.
+func WithTag(tag string) Option {
+	return func(c *Config) {
+		c.Tag = tag
+	}
+}
+
+// WithNameTags sets the struct tags naming fields in violations.
+func WithNameTags(tags ...string) Option {
+	return func(c *Config) {
+		c.NameTags = tags
+	}
+}
+
+// WithRule adds a rule, or replaces the built-in rule with the same name.
+func WithRule(name string, fn RuleFunc) Option {
+	return func(c *Config) {
+		c.Rules[name] = fn
+	}
+}
+
+// Validator validates structs with the rules declared in their tags, the parsed rules are cached per type.
+type Validator struct {
+	config Config
+	types  sync.Map // types maps a reflect.Type to its []field.
+}
+
+// New creates a Validator.
+func New(options ...Option) *Validator {
+	config := GetDefaultConfig()
+	for _, opt := range options {
+		opt(&config)
+	}
+	return &Validator{config: config}
+}
+
+// Default is the Validator used by the package level functions.
+var Default = New()
+
+// Validate validates v with the Default validator.
+func Validate(v any) error {
+	return Default.Validate(v)
+}
+
+// Validate validates v, a struct or a pointer to a struct, and its nested structs. Values of other kinds are valid.
+// Violations are returned as a single 400 errors.HTTPError whose Description is the list of Violation.
+func (val *Validator) Validate(v any) error {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var violations []Violation
+	if err := val.validateStruct(rv, "", &violations); err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	fields := make([]string, len(violations))
+	for i, v := range violations {
+		fields[i] = v.Field
+	}
+	return &errors.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Err: &errors.Error{
+			Code:        ErrorCodeValidation,
+			Message:     "Validation failed for " + strings.Join(fields, ", "),
+			Description: violations,
+		},
+	}
+}
+
+// Violations returns the violations of an error returned by Validate, including wrapped errors.
+func Violations(err error) []Violation {
+	var httpErr *errors.HTTPError
+	if !e.As(err, &httpErr) || httpErr.Err == nil {
+		return nil
+	}
+	violations, _ := httpErr.Err.Description.([]Violation)
+	return violations
+}
+
+// rule is a parsed rule of a tag.
+type rule struct {
+	name  string
+	param string
+	fn    RuleFunc
+}
+
+// rules are the parsed rules of a tag, dive holds the rules applied to the elements of slices, arrays and maps.
+type rules struct {
+	required  bool
+	omitEmpty bool
+	list      []rule
+	dive      *rules
+}
+
+// field is a validated struct field.
+type field struct {
+	index []int
+	name  string
+	rules *rules
+}
+
+func (val *Validator) validateStruct(v reflect.Value, path string, violations *[]Violation) error {
+	fields, err := val.fields(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			continue // nil embedded pointer
+		}
+		if err := val.validateValue(fv, joinPath(path, f.name), f.rules, violations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (val *Validator) validateValue(v reflect.Value, path string, r *rules, violations *[]Violation) error {
+	if isEmpty(v) {
+		if r.required {
+			*violations = append(*violations, Violation{Field: path, Rule: "required", Message: "is required"})
+			return nil
+		}
+		if r.omitEmpty || v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			return nil
+		}
+	}
+	v = indirect(v)
+	for _, rl := range r.list {
+		if err := rl.fn(v, rl.param); err != nil {
+			*violations = append(*violations, Violation{Field: path, Rule: rl.name, Message: err.Error()})
+		}
+	}
+	if r.dive != nil {
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				if err := val.validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", r.dive, violations); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := val.validateValue(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", r.dive, violations); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Struct {
+		return val.validateStruct(v, path, violations)
+	}
+	return nil
+}
+
+// fields returns the validated fields of the struct type, embedded structs without a name are flattened.
+func (val *Validator) fields(t reflect.Type) ([]field, error) {
+	if cached, ok := val.types.Load(t); ok {
+		return cached.([]field), nil
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(val.config.Tag)
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		name, named := val.fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && !named && tag == "" && ft.Kind() == reflect.Struct {
+			embedded, err := val.fields(ft)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range embedded {
+				fields = append(fields, field{index: append([]int{i}, f.index...), name: f.name, rules: f.rules})
+			}
+			continue
+		}
+		r, err := val.parse(tag)
+		if err != nil {
+			return nil, fmt.Errorf("validate.Validator.Validate: invalid rules on %v.%v: %w", t, sf.Name, err)
+		}
+		fields = append(fields, field{index: []int{i}, name: name, rules: r})
+	}
+	val.types.Store(t, fields)
+	return fields, nil
+}
+
+// fieldName returns the name of the field from the first name tag set, or the Go name of the field.
+func (val *Validator) fieldName(sf reflect.StructField) (string, bool) {
+	for _, tag := range val.config.NameTags {
+		if name, ok := sf.Tag.Lookup(tag); ok {
+			if name, _, _ = strings.Cut(name, ","); name != "" {
+				return name, true
+			}
+		}
+	}
+	return sf.Name, false
+}
+
+// parse parses the rules of a tag. The regex rule takes the rest of the tag as its pattern, so it must be the last
+// rule of a tag.
+func (val *Validator) parse(tag string) (*rules, error) {
+	root := &rules{}
+	current := root
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "":
+		case "dive":
+			current.dive = &rules{}
+			current = current.dive
+		case "required":
+			current.required = true
+		case "omitempty":
+			current.omitEmpty = true
+		default:
+			fn, ok := val.config.Rules[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown rule %q", name)
+			}
+			current.list = append(current.list, rule{name: name, param: param, fn: fn})
+		}
+	}
+	return root, nil
+}
+
+// isEmpty reports whether the value is missing: nil pointers and interfaces, empty slices and maps and zero values.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,103 @@
+package validate_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sabariramc/go-kit/errors"
+	"github.com/sabariramc/go-kit/validate"
+	"gotest.tools/v3/assert"
+)
+
+type address struct {
+	City    string `json:"city" validate:"required"`
+	ZipCode string `json:"zipCode" validate:"omitempty,regex=^[0-9]{5}(-[0-9]{4})?$"`
+}
+
+type item struct {
+	SKU      string `json:"sku" validate:"required,len=8"`
+	Quantity int    `json:"quantity" validate:"min=1,max=100"`
+}
+
+type order struct {
+	ID       string            `json:"id" validate:"required,uuid"`
+	Email    string            `json:"email" validate:"omitempty,email"`
+	Status   string            `json:"status" validate:"omitempty,enum=pending|paid|shipped"`
+	Note     *string           `json:"note,omitempty" validate:"max=10"`
+	Address  address           `json:"address"`
+	Items    []item            `json:"items" validate:"required,max=3,dive"`
+	Tags     []string          `json:"tags" validate:"min=1,dive,min=2"`
+	Labels   map[string]string `json:"labels" validate:"dive,required"`
+	internal string
+}
+
+func TestValidate(t *testing.T) {
+	note := "a long note that is too long"
+	o := &order{
+		ID:      "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Email:   "jane@example.com",
+		Status:  "paid",
+		Address: address{City: "Chennai", ZipCode: "60001"},
+		Items:   []item{{SKU: "ABCD1234", Quantity: 2}},
+		Tags:    []string{"ok"},
+	}
+	assert.NilError(t, validate.Validate(o))
+	o.ID = "not-a-uuid"
+	o.Email = "jane"
+	o.Status = "lost"
+	o.Note = &note
+	o.Address = address{ZipCode: "6000"}
+	o.Items = []item{{SKU: "ABCD1234", Quantity: 2}, {SKU: "ABC", Quantity: 0}}
+	o.Tags = []string{"ok", "x"}
+	o.Labels = map[string]string{"team": ""}
+	err := validate.Validate(o)
+	var httpErr *errors.HTTPError
+	assert.Assert(t, reflect.TypeOf(err) == reflect.TypeOf(httpErr))
+	httpErr = err.(*errors.HTTPError)
+	assert.Equal(t, httpErr.StatusCode, 400)
+	assert.Equal(t, httpErr.Err.Code, validate.ErrorCodeValidation)
+	assert.DeepEqual(t, validate.Violations(err), []validate.Violation{
+		{Field: "id", Rule: "uuid", Message: "must be a valid UUID"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "status", Rule: "enum", Message: "must be one of pending, paid, shipped"},
+		{Field: "note", Rule: "max", Message: "length must be at most 10"},
+		{Field: "address.city", Rule: "required", Message: "is required"},
+		{Field: "address.zipCode", Rule: "regex", Message: "must match ^[0-9]{5}(-[0-9]{4})?$"},
+		{Field: "items[1].sku", Rule: "len", Message: "length must be 8"},
+		{Field: "items[1].quantity", Rule: "min", Message: "must be at least 1"},
+		{Field: "tags[1]", Rule: "min", Message: "length must be at least 2"},
+		{Field: "labels[team]", Rule: "required", Message: "is required"},
+	})
+	assert.Assert(t, strings.HasPrefix(httpErr.Err.Message, "Validation failed for id, email"))
+	assert.DeepEqual(t, validate.Violations(fmt.Errorf("handler: %w", err)), validate.Violations(err))
+	o = &order{}
+	assert.DeepEqual(t, validate.Violations(validate.Validate(o)), []validate.Violation{
+		{Field: "id", Rule: "required", Message: "is required"},
+		{Field: "address.city", Rule: "required", Message: "is required"},
+		{Field: "items", Rule: "required", Message: "is required"},
+		{Field: "tags", Rule: "min", Message: "length must be at least 1"},
+	})
+	assert.NilError(t, validate.Validate("not a struct"))
+}
+
+func TestValidateCustomRule(t *testing.T) {
+	type event struct {
+		Name string `body:"name" validate:"required,prefix=evt_"`
+	}
+	v := validate.New(validate.WithNameTags("body"), validate.WithRule("prefix", func(v reflect.Value, param string) error {
+		if !strings.HasPrefix(v.String(), param) {
+			return &errors.Error{Message: "must start with " + param}
+		}
+		return nil
+	}))
+	assert.NilError(t, v.Validate(event{Name: "evt_created"}))
+	assert.DeepEqual(t, validate.Violations(v.Validate(event{Name: "created"})), []validate.Violation{
+		{Field: "name", Rule: "prefix", Message: "message: must start with evt_"},
+	})
+	err := validate.Validate(struct {
+		Name string `validate:"unknown"`
+	}{})
+	assert.ErrorContains(t, err, `unknown rule "unknown"`)
+}